@@ -8,8 +8,15 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/andiq123/cetatenie-analyzer/internal/archive"
 	"github.com/andiq123/cetatenie-analyzer/internal/database"
 	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/feed"
+	"github.com/andiq123/cetatenie-analyzer/internal/fetcher"
+	"github.com/andiq123/cetatenie-analyzer/internal/notification"
+	"github.com/andiq123/cetatenie-analyzer/internal/oath"
+	"github.com/andiq123/cetatenie-analyzer/internal/retention"
+	"github.com/andiq123/cetatenie-analyzer/internal/revision"
 	"github.com/andiq123/cetatenie-analyzer/internal/subscription_checker"
 	"github.com/andiq123/cetatenie-analyzer/internal/telegram_bot"
 	"github.com/joho/godotenv"
@@ -32,10 +39,20 @@ func main() {
 	}
 
 	subscriptionService := database.NewSubscriptionService(db)
-	decreeService := decree.NewProcessor()
-	bot := telegram_bot.NewBot(db)
+	profileService := database.NewProfileService(db)
+	revisionArchive := archive.NewService(database.NewArchiveService(db))
+	fileFetcher, err := fetcher.New(revisionArchive)
+	if err != nil {
+		fmt.Printf("Failed to create fetcher: %v\n", err)
+		os.Exit(1)
+	}
+	decreeService := decree.NewProcessor(fileFetcher)
+	bot := telegram_bot.NewBot(db, decreeService, revisionArchive)
 
-	checker := subscription_checker.NewService(subscriptionService, decreeService, bot)
+	notifier := notification.NewService(database.NewNotificationService(db), profileService, bot)
+	revisions := revision.NewService(decreeService, database.NewRevisionService(db))
+	checker := subscription_checker.NewService(subscriptionService, profileService, decreeService, notifier, revisions, oath.NewService(fileFetcher))
+	bot.SetChecker(checker)
 
 	fmt.Println("Starting subscription checker...")
 	checkerErr := make(chan error, 1)
@@ -59,6 +76,67 @@ func main() {
 		}
 	}()
 
+	fmt.Println("Starting notification delivery...")
+	go func() {
+		// Deferred notifications are due on the hour, check often enough to deliver them promptly
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := notifier.DeliverDue(ctx); err != nil {
+					fmt.Printf("Error delivering notifications: %v\n", err)
+				}
+			}
+		}
+	}()
+
+	fmt.Println("Starting data retention job...")
+	retentionJob := retention.NewService(database.NewPrivacyService(db))
+	go func() {
+		ticker := time.NewTicker(24 * time.Hour)
+		defer ticker.Stop()
+
+		if err := retentionJob.PurgeExpiredData(); err != nil {
+			fmt.Printf("Error in initial data retention run: %v\n", err)
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := retentionJob.PurgeExpiredData(); err != nil {
+					fmt.Printf("Error purging expired data: %v\n", err)
+				}
+			}
+		}
+	}()
+
+	if feedConfig, ok := feed.LoadConfig(); ok {
+		fmt.Println("Starting channel feed...")
+		channelFeed := feed.NewService(feedConfig, decreeService, revisions, database.NewRevisionService(db), bot)
+		go func() {
+			// The PDFs are downloaded again once a day, checking hourly announces a new revision soon after
+			ticker := time.NewTicker(time.Hour)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := channelFeed.PublishNewRevisions(ctx); err != nil {
+						fmt.Printf("Error publishing channel feed: %v\n", err)
+					}
+				}
+			}
+		}()
+	}
+
 	fmt.Println("Starting Telegram bot...")
 	botErr := make(chan error, 1)
 	go func() {
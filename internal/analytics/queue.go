@@ -0,0 +1,44 @@
+// Package analytics derives statistics about the dossiers of an annual PDF
+package analytics
+
+import (
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+)
+
+// Position describes where a pending dossier stands among the dossiers of its year
+type Position struct {
+	Number dossier.Number
+	// PendingBefore counts the dossiers registered before it that are still pending
+	PendingBefore int
+	// HighestResolved is the highest resolved number of the year, 0 if none
+	HighestResolved int
+	Resolved        int
+	Total           int
+}
+
+// PercentResolved is the share of the year's dossiers already resolved
+func (p Position) PercentResolved() float64 {
+	if p.Total == 0 {
+		return 0
+	}
+	return float64(p.Resolved) * 100 / float64(p.Total)
+}
+
+// Overtaken reports whether dossiers registered after this one were already
+// resolved, the frontier is then past it
+func (p Position) Overtaken() bool {
+	return p.HighestResolved > p.Number.Value
+}
+
+// QueuePosition computes the position of a dossier within its year's document
+func QueuePosition(doc *decree.Document, number dossier.Number) Position {
+	position := Position{Number: number, PendingBefore: doc.PendingBefore(number), Total: len(doc.Entries)}
+	for other, entry := range doc.Entries {
+		if entry.State() == decree.StateFoundAndResolved {
+			position.Resolved++
+			position.HighestResolved = max(position.HighestResolved, other.Value)
+		}
+	}
+	return position
+}
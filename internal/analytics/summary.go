@@ -0,0 +1,116 @@
+package analytics
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+)
+
+// latestOrderDays is the number of most recent order dates listed in a summary
+const latestOrderDays = 5
+
+// OrderDay is a date on which orders were issued and how many dossiers they resolved
+type OrderDay struct {
+	Date  time.Time
+	Count int
+}
+
+// YearSummary aggregates the dossiers of an annual PDF
+type YearSummary struct {
+	Year            int
+	Revision        string
+	Total           int
+	Resolved        int
+	Pending         int
+	HighestResolved int
+	// LatestOrders lists the most recent order dates, newest first
+	LatestOrders []OrderDay
+	// Weeks is the weekly throughput of the last complete weeks, oldest first
+	Weeks      []decree.WeekThroughput
+	WeeklyRate float64
+	ComputedAt time.Time
+}
+
+// PercentResolved is the share of the listed dossiers already resolved
+func (s YearSummary) PercentResolved() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Resolved) * 100 / float64(s.Total)
+}
+
+// Summarize aggregates a parsed annual document. firstSeen dates resolutions
+// whose order date is not printed, as in decree.Document.Statistics.
+func Summarize(doc *decree.Document, firstSeen map[dossier.Number]time.Time, now time.Time) YearSummary {
+	stats := doc.Statistics(firstSeen, now)
+	summary := YearSummary{
+		Year:       doc.Year,
+		Revision:   doc.Revision,
+		Total:      len(doc.Entries),
+		Resolved:   stats.Resolved,
+		Pending:    stats.Pending,
+		Weeks:      stats.Weeks,
+		WeeklyRate: stats.WeeklyRate,
+		ComputedAt: now,
+	}
+
+	perDay := make(map[time.Time]int)
+	for number, entry := range doc.Entries {
+		if entry.State() != decree.StateFoundAndResolved {
+			continue
+		}
+		summary.HighestResolved = max(summary.HighestResolved, number.Value)
+		if !entry.OrderDate.IsZero() && !entry.OrderDate.After(now) {
+			perDay[entry.OrderDate]++
+		}
+	}
+	for date, count := range perDay {
+		summary.LatestOrders = append(summary.LatestOrders, OrderDay{Date: date, Count: count})
+	}
+	sort.Slice(summary.LatestOrders, func(i, j int) bool {
+		return summary.LatestOrders[i].Date.After(summary.LatestOrders[j].Date)
+	})
+	if len(summary.LatestOrders) > latestOrderDays {
+		summary.LatestOrders = summary.LatestOrders[:latestOrderDays]
+	}
+
+	return summary
+}
+
+// SummaryCache keeps the summary of each year until the PDF revision
+// changes or the day ends, when the weekly throughput moves on
+type SummaryCache struct {
+	mu        sync.Mutex
+	summaries map[int]YearSummary
+}
+
+// NewSummaryCache creates an empty summary cache
+func NewSummaryCache() *SummaryCache {
+	return &SummaryCache{summaries: make(map[int]YearSummary)}
+}
+
+// Get returns the cached summary of a document's revision, computing it with
+// summarize when missing or stale
+func (c *SummaryCache) Get(doc *decree.Document, now time.Time, summarize func() YearSummary) YearSummary {
+	c.mu.Lock()
+	cached, ok := c.summaries[doc.Year]
+	c.mu.Unlock()
+	if ok && cached.Revision == doc.Revision && sameDay(cached.ComputedAt, now) {
+		return cached
+	}
+
+	summary := summarize()
+	c.mu.Lock()
+	c.summaries[doc.Year] = summary
+	c.mu.Unlock()
+	return summary
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
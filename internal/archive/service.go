@@ -0,0 +1,130 @@
+// Package archive keeps every distinct revision of the annual PDFs, so a
+// lookup can be repeated against the document as it was on a past date
+package archive
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+)
+
+// defaultDir is used when ARCHIVE_DIR is not set
+const defaultDir = "./archive"
+
+// Service defines the interface for the revision archive. It implements
+// fetcher.Archive.
+type Service interface {
+	Store(year int, data []byte, header http.Header, fetchedAt time.Time) error
+	List(year int, limit int) ([]database.ArchivedRevision, error)
+	Find(year int, hashPrefix string) (*database.ArchivedRevision, []byte, error)
+	At(year int, at time.Time) (*database.ArchivedRevision, []byte, error)
+}
+
+// service implements the Service interface
+type service struct {
+	archiveService database.ArchiveService
+	dir            string
+}
+
+// NewService creates an archive storing the PDFs in ARCHIVE_DIR (./archive by default)
+func NewService(archiveService database.ArchiveService) Service {
+	dir := os.Getenv("ARCHIVE_DIR")
+	if dir == "" {
+		dir = defaultDir
+	}
+	return &service{
+		archiveService: archiveService,
+		dir:            dir,
+	}
+}
+
+// Store archives a downloaded revision. Content already archived for the
+// year is not written again, only its last seen time is refreshed.
+func (s *service) Store(year int, data []byte, header http.Header, fetchedAt time.Time) error {
+	hash := sha256.Sum256(data)
+	revision := &database.ArchivedRevision{
+		Year:       year,
+		Hash:       hex.EncodeToString(hash[:]),
+		Size:       len(data),
+		FetchedAt:  fetchedAt,
+		LastSeenAt: fetchedAt,
+	}
+
+	path := s.path(year, revision.Hash)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := writeFile(path, data); err != nil {
+			return fmt.Errorf("error writing archived revision: %w", err)
+		}
+	}
+
+	headers, err := json.Marshal(header)
+	if err != nil {
+		return fmt.Errorf("error encoding headers: %w", err)
+	}
+	revision.Headers = string(headers)
+
+	created, err := s.archiveService.RecordArchivedRevision(revision)
+	if err != nil {
+		return fmt.Errorf("error recording archived revision: %w", err)
+	}
+	if created {
+		fmt.Printf("Archived revision %s of %d (%d bytes)\n", revision.Hash[:12], year, len(data))
+	}
+	return nil
+}
+
+// List returns the newest archived revisions, of every year when year is 0
+func (s *service) List(year int, limit int) ([]database.ArchivedRevision, error) {
+	return s.archiveService.GetArchivedRevisions(year, limit)
+}
+
+// Find returns the archived revision of a year whose hash starts with hashPrefix, with its content
+func (s *service) Find(year int, hashPrefix string) (*database.ArchivedRevision, []byte, error) {
+	revision, err := s.archiveService.FindArchivedRevision(year, hashPrefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.load(revision)
+}
+
+// At returns the revision of a year that was current at the given time, with its content
+func (s *service) At(year int, at time.Time) (*database.ArchivedRevision, []byte, error) {
+	revision, err := s.archiveService.GetArchivedRevisionAt(year, at)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.load(revision)
+}
+
+func (s *service) load(revision *database.ArchivedRevision) (*database.ArchivedRevision, []byte, error) {
+	data, err := os.ReadFile(s.path(revision.Year, revision.Hash))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading archived revision: %w", err)
+	}
+	return revision, data, nil
+}
+
+func (s *service) path(year int, hash string) string {
+	return filepath.Join(s.dir, strconv.Itoa(year), hash+".pdf")
+}
+
+// writeFile writes through a temporary file so a crash never leaves a
+// truncated revision under its final name
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
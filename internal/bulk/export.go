@@ -0,0 +1,48 @@
+package bulk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"time"
+)
+
+// ExportRow is a subscription written to an exported document
+type ExportRow struct {
+	DecreeNumber string
+	Label        string
+	Note         string
+	State        string
+	CheckedAt    *time.Time
+}
+
+var exportHeader = []string{"dosar", "eticheta", "nota", "ultima_stare", "verificat_la"}
+
+// WriteCSV writes the rows as a CSV document that spreadsheet applications
+// open with the right encoding. The first two columns can be imported back.
+func WriteCSV(rows []ExportRow) ([]byte, error) {
+	records := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		checkedAt := ""
+		if row.CheckedAt != nil {
+			checkedAt = row.CheckedAt.Format(time.RFC3339)
+		}
+		records = append(records, []string{row.DecreeNumber, row.Label, row.Note, row.State, checkedAt})
+	}
+	return WriteRecords(exportHeader, records)
+}
+
+// WriteRecords writes a header and its records as a CSV document starting
+// with the byte order mark spreadsheet applications need to detect UTF-8
+func WriteRecords(header []string, records [][]string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(byteOrderMark)
+
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
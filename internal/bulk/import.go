@@ -0,0 +1,203 @@
+// Package bulk reads and writes the documents used to import and export
+// subscriptions in bulk
+package bulk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+)
+
+// MaxRows is the number of rows accepted in a single imported document
+const MaxRows = 1000
+
+// dossierFields name the dossier column of CSV headers and the dossier field of JSON objects
+var dossierFields = []string{"dosar", "dossier", "decree_number"}
+
+// byteOrderMark is written by spreadsheet applications at the start of CSV files
+const byteOrderMark = "\uFEFF"
+
+// Row is a dossier read from an imported document. Err is set when the row
+// cannot be imported; DecreeNumber is canonical otherwise. Rows holding a
+// range (1000-1500/RD/2022 or */RD/2022) also set Range.
+type Row struct {
+	Line         int
+	Raw          string
+	DecreeNumber string
+	Label        string
+	Range        *dossier.Range
+	Err          error
+}
+
+// ParseDocument reads the dossiers from a CSV (.csv, .txt) or JSON (.json)
+// document. CSV rows hold the dossier number and an optional label; a header
+// row is skipped. JSON documents hold an array of dossier numbers or of
+// objects with "dosar" and "eticheta" fields.
+func ParseDocument(filename string, data []byte) ([]Row, error) {
+	data = bytes.TrimPrefix(data, []byte(byteOrderMark))
+
+	var rows []Row
+	var err error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".txt":
+		rows, err = parseCSV(data)
+	case ".json":
+		rows, err = parseJSON(data)
+	default:
+		return nil, fmt.Errorf("format nesuportat, trimite un fișier .csv sau .json")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("fișierul nu conține niciun dosar")
+	}
+	if len(rows) > MaxRows {
+		return nil, fmt.Errorf("fișierul conține %d rânduri, maximul este %d", len(rows), MaxRows)
+	}
+	markDuplicates(rows)
+	return rows, nil
+}
+
+func parseCSV(data []byte) ([]Row, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = detectDelimiter(data)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows []Row
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fișier CSV invalid: %w", err)
+		}
+		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		label := ""
+		if len(record) > 1 {
+			label = record[1]
+		}
+		row := newRow(line, record[0], label)
+
+		// The first row may name the columns instead of holding a dossier
+		if line == 1 && row.Err != nil && isHeader(row.Raw) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// isHeader reports whether a first cell names the dossier column: a known
+// column name, or any text without digits. A mistyped dossier is reported.
+func isHeader(cell string) bool {
+	cell = strings.ToLower(cell)
+	for _, name := range dossierFields {
+		if cell == name {
+			return true
+		}
+	}
+	return !strings.ContainsAny(cell, "0123456789")
+}
+
+// detectDelimiter picks ";" for files exported by spreadsheets configured
+// for locales that use the comma as decimal separator
+func detectDelimiter(data []byte) rune {
+	firstLine, _, _ := bytes.Cut(data, []byte("\n"))
+	if bytes.Contains(firstLine, []byte(";")) && !bytes.Contains(firstLine, []byte(",")) {
+		return ';'
+	}
+	return ','
+}
+
+func parseJSON(data []byte) ([]Row, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("fișier JSON invalid, se așteaptă o listă de dosare: %w", err)
+	}
+
+	rows := make([]Row, 0, len(items))
+	for i, item := range items {
+		var number string
+		if err := json.Unmarshal(item, &number); err == nil {
+			rows = append(rows, newRow(i+1, number, ""))
+			continue
+		}
+
+		// Exports from other tools may hold numbers, e.g. a numeric label
+		var fields map[string]any
+		decoder := json.NewDecoder(bytes.NewReader(item))
+		decoder.UseNumber()
+		if err := decoder.Decode(&fields); err != nil {
+			rows = append(rows, Row{Line: i + 1, Raw: string(item), Err: fmt.Errorf("element invalid")})
+			continue
+		}
+		rows = append(rows, newRow(i+1, firstField(fields, dossierFields...), firstField(fields, "eticheta", "label")))
+	}
+	return rows, nil
+}
+
+func newRow(line int, raw, label string) Row {
+	row := Row{Line: line, Raw: strings.TrimSpace(raw), Label: strings.TrimSpace(label)}
+
+	r, rest, err := dossier.ParseRange(row.Raw)
+	switch {
+	case err == nil && rest == "":
+		row.Range = &r
+		row.DecreeNumber = r.String()
+		return row
+	case err != nil && !errors.Is(err, dossier.ErrNotRange):
+		row.Err = err
+		return row
+	}
+
+	match, err := dossier.Parse(row.Raw)
+	if err != nil {
+		row.Err = err
+		return row
+	}
+	row.DecreeNumber = match.Canonical()
+	return row
+}
+
+// firstField returns the first of the named fields holding a string, number
+// or boolean, formatted as text
+func firstField(fields map[string]any, names ...string) string {
+	for _, name := range names {
+		switch value := fields[name].(type) {
+		case nil, map[string]any, []any:
+			continue
+		default:
+			return fmt.Sprint(value)
+		}
+	}
+	return ""
+}
+
+// markDuplicates rejects dossiers already listed on an earlier row
+func markDuplicates(rows []Row) {
+	seen := make(map[string]int, len(rows))
+	for i := range rows {
+		if rows[i].Err != nil {
+			continue
+		}
+		if line, ok := seen[rows[i].DecreeNumber]; ok {
+			rows[i].Err = fmt.Errorf("duplicat al rândului %d", line)
+			continue
+		}
+		seen[rows[i].DecreeNumber] = rows[i].Line
+	}
+}
@@ -0,0 +1,74 @@
+package bulk
+
+import "testing"
+
+func TestParseDocumentJSON(t *testing.T) {
+	data := `[
+		"1/RD/2023",
+		{"dosar": "2/RD/2023", "eticheta": "Mama"},
+		{"dossier": "3/RD/2023", "label": 42},
+		{"decree_number": "4/RD/2023", "eticheta": true, "nota": {"x": 1}},
+		{"dosar": null, "dossier": "5/RD/2023", "eticheta": 12345678901234567890},
+		{"dosar": ["6/RD/2023"]},
+		7
+	]`
+
+	rows, err := ParseDocument("lista.json", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		number string
+		label  string
+		err    bool
+	}{
+		{"1/RD/2023", "", false},
+		{"2/RD/2023", "Mama", false},
+		{"3/RD/2023", "42", false},
+		{"4/RD/2023", "true", false},
+		{"5/RD/2023", "12345678901234567890", false},
+		{"", "", true},
+		{"", "", true},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d: %+v", len(rows), len(want), rows)
+	}
+	for i, w := range want {
+		row := rows[i]
+		if row.DecreeNumber != w.number || row.Label != w.label || (row.Err != nil) != w.err {
+			t.Errorf("row %d = {%q %q %v}, want {%q %q err=%v}", i+1, row.DecreeNumber, row.Label, row.Err, w.number, w.label, w.err)
+		}
+	}
+}
+
+func TestParseDocumentCSVHeader(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantFirst string
+		wantErr   bool
+		wantRows  int
+	}{
+		{name: "known header", data: "dosar,eticheta\n1/RD/2023,Mama\n", wantFirst: "1/RD/2023", wantRows: 1},
+		{name: "header without digits", data: "Număr dosar;Etichetă\n1/RD/2023;Mama\n", wantFirst: "1/RD/2023", wantRows: 1},
+		{name: "no header", data: "1/RD/2023,Mama\n2/RD/2023\n", wantFirst: "1/RD/2023", wantRows: 2},
+		{name: "mistyped first dossier", data: "1/RX/2023,Mama\n2/RD/2023\n", wantErr: true, wantRows: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ParseDocument("lista.csv", []byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(rows) != tt.wantRows {
+				t.Fatalf("got %d rows, want %d: %+v", len(rows), tt.wantRows, rows)
+			}
+			first := rows[0]
+			if (first.Err != nil) != tt.wantErr || first.DecreeNumber != tt.wantFirst {
+				t.Errorf("first row %+v, want %q rejected=%v", first, tt.wantFirst, tt.wantErr)
+			}
+		})
+	}
+}
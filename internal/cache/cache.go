@@ -73,3 +73,11 @@ func (c *Cache) Cleanup() {
 		}
 	}
 }
+
+// Clear removes all items from the cache
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items = make(map[string]CacheItem)
+}
@@ -0,0 +1,75 @@
+// Package chart renders simple PNG charts without external dependencies
+package chart
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"strconv"
+)
+
+const (
+	width  = 720
+	height = 360
+	margin = 24
+	// labelHeight is the space under the bars for their labels
+	labelHeight = 28
+	// valueHeight is the space above the tallest bar for its value
+	valueHeight = 20
+)
+
+var (
+	background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	barColor   = color.RGBA{0x3b, 0x82, 0xf6, 0xff}
+	axisColor  = color.RGBA{0x9c, 0xa3, 0xaf, 0xff}
+	textColor  = color.RGBA{0x37, 0x41, 0x51, 0xff}
+)
+
+// Bar is a labelled value of a bar chart. Labels may only use digits and . / -
+type Bar struct {
+	Label string
+	Value int
+}
+
+// BarChart renders the bars left to right as a PNG image, each with its
+// value above and its label below
+func BarChart(bars []Bar) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{background}, image.Point{}, draw.Src)
+
+	baseline := height - margin - labelHeight
+	fill(img, image.Rect(margin, baseline, width-margin, baseline+1), axisColor)
+
+	if len(bars) > 0 {
+		highest := 1
+		for _, bar := range bars {
+			highest = max(highest, bar.Value)
+		}
+
+		slot := (width - 2*margin) / len(bars)
+		barWidth := max(slot*2/3, 1)
+		plotHeight := baseline - margin - valueHeight
+		for i, bar := range bars {
+			left := margin + i*slot + (slot-barWidth)/2
+			center := left + barWidth/2
+			top := baseline - bar.Value*plotHeight/highest
+			fill(img, image.Rect(left, top, left+barWidth, baseline), barColor)
+
+			value := strconv.Itoa(bar.Value)
+			drawText(img, value, center-textWidth(value)/2, top-glyphHeight*scale-4)
+			drawText(img, bar.Label, center-textWidth(bar.Label)/2, baseline+8)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func fill(img *image.RGBA, rect image.Rectangle, c color.Color) {
+	draw.Draw(img, rect, &image.Uniform{c}, image.Point{}, draw.Src)
+}
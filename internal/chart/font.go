@@ -0,0 +1,57 @@
+package chart
+
+import "image"
+
+const (
+	glyphWidth  = 3
+	glyphHeight = 5
+	// scale enlarges every glyph pixel to a square of this size
+	scale = 2
+	// spacing separates two glyphs, in image pixels
+	spacing = 2
+)
+
+// glyphs is a 3x5 bitmap font for the characters used in chart labels. Each
+// row is read from the most significant of its three bits.
+var glyphs = map[rune][glyphHeight]byte{
+	'0': {0b111, 0b101, 0b101, 0b101, 0b111},
+	'1': {0b010, 0b110, 0b010, 0b010, 0b111},
+	'2': {0b111, 0b001, 0b111, 0b100, 0b111},
+	'3': {0b111, 0b001, 0b111, 0b001, 0b111},
+	'4': {0b101, 0b101, 0b111, 0b001, 0b001},
+	'5': {0b111, 0b100, 0b111, 0b001, 0b111},
+	'6': {0b111, 0b100, 0b111, 0b101, 0b111},
+	'7': {0b111, 0b001, 0b010, 0b010, 0b010},
+	'8': {0b111, 0b101, 0b111, 0b101, 0b111},
+	'9': {0b111, 0b101, 0b111, 0b001, 0b111},
+	'.': {0b000, 0b000, 0b000, 0b000, 0b010},
+	'/': {0b001, 0b001, 0b010, 0b100, 0b100},
+	'-': {0b000, 0b000, 0b111, 0b000, 0b000},
+}
+
+// drawText draws text with its top left corner at x, y; characters without
+// a glyph are left blank
+func drawText(img *image.RGBA, text string, x, y int) {
+	for _, r := range text {
+		glyph := glyphs[r]
+		for row := range glyphHeight {
+			for col := range glyphWidth {
+				if glyph[row]&(1<<(glyphWidth-1-col)) == 0 {
+					continue
+				}
+				px, py := x+col*scale, y+row*scale
+				fill(img, image.Rect(px, py, px+scale, py+scale), textColor)
+			}
+		}
+		x += glyphWidth*scale + spacing
+	}
+}
+
+// textWidth is the width drawText needs for text
+func textWidth(text string) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+	return n*(glyphWidth*scale+spacing) - spacing
+}
@@ -0,0 +1,27 @@
+package database
+
+import "time"
+
+// ArchivedRevision is a distinct downloaded revision of an annual PDF. The
+// file itself is kept in the archive directory, named after its hash.
+// FetchedAt is the first download of this content, LastSeenAt the latest.
+type ArchivedRevision struct {
+	ID   uint   `gorm:"primaryKey"`
+	Year int    `gorm:"uniqueIndex:idx_archived_revisions_year_hash"`
+	Hash string `gorm:"uniqueIndex:idx_archived_revisions_year_hash"`
+	Size int
+	// Headers holds the HTTP response headers of the first download, as JSON
+	Headers    string
+	FetchedAt  time.Time `gorm:"index"`
+	LastSeenAt time.Time
+}
+
+// ArchivedObservation records when a year's PDF started serving a revision.
+// A row is added each time the downloaded hash differs from the previous
+// download, so content that comes back after another revision is seen again.
+type ArchivedObservation struct {
+	ID     uint `gorm:"primaryKey"`
+	Year   int  `gorm:"index:idx_archived_observations_year_seen"`
+	Hash   string
+	SeenAt time.Time `gorm:"index:idx_archived_observations_year_seen"`
+}
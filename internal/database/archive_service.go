@@ -0,0 +1,125 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrArchivedRevisionNotFound is returned when no archived revision matches
+	ErrArchivedRevisionNotFound = errors.New("archived revision not found")
+	// ErrAmbiguousRevision is returned when a hash prefix matches several revisions
+	ErrAmbiguousRevision = errors.New("hash prefix matches several archived revisions")
+)
+
+type ArchiveService interface {
+	RecordArchivedRevision(revision *ArchivedRevision) (bool, error)
+	GetArchivedRevisions(year int, limit int) ([]ArchivedRevision, error)
+	FindArchivedRevision(year int, hashPrefix string) (*ArchivedRevision, error)
+	GetArchivedRevisionAt(year int, at time.Time) (*ArchivedRevision, error)
+}
+
+type archiveService struct {
+	db *gorm.DB
+}
+
+func NewArchiveService(db *gorm.DB) ArchiveService {
+	return &archiveService{db: db}
+}
+
+// RecordArchivedRevision stores a downloaded revision, or only refreshes its
+// LastSeenAt when the same content was archived before, and observes it when
+// it differs from the previous download. It reports whether the revision is new.
+func (s *archiveService) RecordArchivedRevision(revision *ArchivedRevision) (bool, error) {
+	created := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := observeRevision(tx, revision); err != nil {
+			return err
+		}
+
+		var existing ArchivedRevision
+		err := tx.Where("year = ? AND hash = ?", revision.Year, revision.Hash).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			created = true
+			return tx.Create(revision).Error
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&existing).Update("last_seen_at", revision.LastSeenAt).Error
+	})
+	return created, err
+}
+
+// observeRevision records that the year's PDF serves the revision since its
+// download, unless the previous download already served it
+func observeRevision(tx *gorm.DB, revision *ArchivedRevision) error {
+	var latest ArchivedObservation
+	err := tx.Where("year = ?", revision.Year).Order("seen_at DESC, id DESC").First(&latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err == nil && latest.Hash == revision.Hash {
+		return nil
+	}
+	return tx.Create(&ArchivedObservation{Year: revision.Year, Hash: revision.Hash, SeenAt: revision.LastSeenAt}).Error
+}
+
+// GetArchivedRevisions returns the newest archived revisions, of every year when year is 0
+func (s *archiveService) GetArchivedRevisions(year int, limit int) ([]ArchivedRevision, error) {
+	query := s.db.Order("fetched_at DESC").Limit(limit)
+	if year != 0 {
+		query = query.Where("year = ?", year)
+	}
+
+	var revisions []ArchivedRevision
+	if err := query.Find(&revisions).Error; err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// FindArchivedRevision returns the revision of a year whose hash starts with hashPrefix
+func (s *archiveService) FindArchivedRevision(year int, hashPrefix string) (*ArchivedRevision, error) {
+	var revisions []ArchivedRevision
+	err := s.db.Where("year = ? AND hash LIKE ?", year, hashPrefix+"%").Limit(2).Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	switch len(revisions) {
+	case 0:
+		return nil, ErrArchivedRevisionNotFound
+	case 1:
+		return &revisions[0], nil
+	default:
+		return nil, ErrAmbiguousRevision
+	}
+}
+
+// GetArchivedRevisionAt returns the revision of a year that was current at
+// the given time: the one served by the last download observed before it.
+// Times before the first observation, archived before observations were
+// recorded, fall back to the last revision first downloaded before them.
+func (s *archiveService) GetArchivedRevisionAt(year int, at time.Time) (*ArchivedRevision, error) {
+	var observation ArchivedObservation
+	err := s.db.Where("year = ? AND seen_at <= ?", year, at).Order("seen_at DESC, id DESC").First(&observation).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var revision ArchivedRevision
+	if err == nil {
+		err = s.db.Where("year = ? AND hash = ?", year, observation.Hash).First(&revision).Error
+	} else {
+		err = s.db.Where("year = ? AND fetched_at <= ?", year, at).Order("fetched_at DESC").First(&revision).Error
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrArchivedRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestGetArchivedRevisionAtFollowsReverts(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&ArchivedRevision{}, &ArchivedObservation{}); err != nil {
+		t.Fatal(err)
+	}
+	s := NewArchiveService(db)
+
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	downloads := []string{"aaaa", "aaaa", "bbbb", "bbbb", "aaaa", "aaaa"}
+	for i, hash := range downloads {
+		at := start.Add(time.Duration(i) * time.Hour)
+		if _, err := s.RecordArchivedRevision(&ArchivedRevision{Year: 2023, Hash: hash, FetchedAt: at, LastSeenAt: at}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var observations int64
+	db.Model(&ArchivedObservation{}).Count(&observations)
+	if observations != 3 {
+		t.Errorf("recorded %d observations, want one per change (3)", observations)
+	}
+
+	tests := []struct {
+		at   time.Time
+		want string
+	}{
+		{at: start.Add(30 * time.Minute), want: "aaaa"},
+		{at: start.Add(2 * time.Hour), want: "bbbb"},
+		{at: start.Add(3*time.Hour + 30*time.Minute), want: "bbbb"},
+		{at: start.Add(4 * time.Hour), want: "aaaa"},
+		{at: start.Add(48 * time.Hour), want: "aaaa"},
+	}
+	for _, tt := range tests {
+		revision, err := s.GetArchivedRevisionAt(2023, tt.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if revision.Hash != tt.want {
+			t.Errorf("revision at %s is %s, want %s", tt.at.Format(time.TimeOnly), revision.Hash, tt.want)
+		}
+	}
+
+	if _, err := s.GetArchivedRevisionAt(2023, start.Add(-time.Hour)); err != ErrArchivedRevisionNotFound {
+		t.Errorf("before the first download got %v, want ErrArchivedRevisionNotFound", err)
+	}
+}
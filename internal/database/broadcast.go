@@ -0,0 +1,28 @@
+package database
+
+import "time"
+
+// Broadcast statuses
+const (
+	BroadcastDraft     = "draft"
+	BroadcastRunning   = "running"
+	BroadcastCompleted = "completed"
+	BroadcastCancelled = "cancelled"
+)
+
+// Broadcast is an announcement sent to every known chat. Cursor holds the
+// last chat ID processed so an interrupted broadcast can resume.
+type Broadcast struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedBy int64
+	Text      string
+	Status    string `gorm:"index"`
+	Cursor    int64
+	Total     int
+	Sent      int
+	Failed    int
+	Blocked   int
+	LastError string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"math"
+
+	"gorm.io/gorm"
+)
+
+type BroadcastService interface {
+	CreateBroadcast(createdBy int64, text string) (*Broadcast, error)
+	GetBroadcast(id uint) (*Broadcast, error)
+	SaveBroadcast(broadcast *Broadcast) error
+	GetRunningBroadcasts() ([]Broadcast, error)
+}
+
+type broadcastService struct {
+	db *gorm.DB
+}
+
+func NewBroadcastService(db *gorm.DB) BroadcastService {
+	return &broadcastService{db: db}
+}
+
+func (s *broadcastService) CreateBroadcast(createdBy int64, text string) (*Broadcast, error) {
+	broadcast := Broadcast{
+		CreatedBy: createdBy,
+		Text:      text,
+		Status:    BroadcastDraft,
+		// Group chats have negative IDs, start before every chat
+		Cursor: math.MinInt64,
+	}
+	if err := s.db.Create(&broadcast).Error; err != nil {
+		return nil, err
+	}
+	return &broadcast, nil
+}
+
+func (s *broadcastService) GetBroadcast(id uint) (*Broadcast, error) {
+	var broadcast Broadcast
+	if err := s.db.First(&broadcast, id).Error; err != nil {
+		return nil, err
+	}
+	return &broadcast, nil
+}
+
+func (s *broadcastService) SaveBroadcast(broadcast *Broadcast) error {
+	return s.db.Save(broadcast).Error
+}
+
+func (s *broadcastService) GetRunningBroadcasts() ([]Broadcast, error) {
+	var broadcasts []Broadcast
+	err := s.db.Where("status = ?", BroadcastRunning).Order("id").Find(&broadcasts).Error
+	if err != nil {
+		return nil, err
+	}
+	return broadcasts, nil
+}
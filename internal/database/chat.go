@@ -0,0 +1,12 @@
+package database
+
+import "time"
+
+// Chat is a chat the bot has interacted with
+type Chat struct {
+	ChatID    int64 `gorm:"primaryKey;autoIncrement:false"`
+	Type      string
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Blocked   bool `gorm:"index"`
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChatService interface {
+	TouchChat(chatID int64, chatType string) error
+	MarkBlocked(chatID int64) error
+	CountActiveChats() (int64, error)
+	GetActiveChatsAfter(chatID int64, limit int) ([]Chat, error)
+}
+
+type chatService struct {
+	db *gorm.DB
+}
+
+func NewChatService(db *gorm.DB) ChatService {
+	return &chatService{db: db}
+}
+
+// TouchChat records activity in a chat, registering it on first contact.
+// A chat that writes to the bot again is no longer considered blocked.
+func (s *chatService) TouchChat(chatID int64, chatType string) error {
+	now := time.Now()
+	chat := Chat{
+		ChatID:    chatID,
+		Type:      chatType,
+		FirstSeen: now,
+		LastSeen:  now,
+	}
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chat_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"type", "last_seen", "blocked"}),
+	}).Create(&chat).Error
+}
+
+// MarkBlocked flags a chat that can no longer be reached, e.g. because the
+// user blocked the bot or the bot was removed from the group
+func (s *chatService) MarkBlocked(chatID int64) error {
+	return s.db.Model(&Chat{}).Where("chat_id = ?", chatID).Update("blocked", true).Error
+}
+
+func (s *chatService) CountActiveChats() (int64, error) {
+	var count int64
+	err := s.db.Model(&Chat{}).Where("blocked = ?", false).Count(&count).Error
+	return count, err
+}
+
+// GetActiveChatsAfter returns up to limit reachable chats with an ID greater
+// than chatID, ordered by ID, so callers can page through all chats
+func (s *chatService) GetActiveChatsAfter(chatID int64, limit int) ([]Chat, error) {
+	var chats []Chat
+	err := s.db.Where("blocked = ? AND chat_id > ?", false, chatID).Order("chat_id").Limit(limit).Find(&chats).Error
+	if err != nil {
+		return nil, err
+	}
+	return chats, nil
+}
@@ -1,6 +1,8 @@
 package database
 
 import (
+	"time"
+
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
@@ -10,6 +12,25 @@ func InitDb() (*gorm.DB, error) {
 	if err != nil {
 		return nil, err
 	}
-	db.AutoMigrate(&Subscription{})
+	// Subscriptions used to be unique per decree number, which prevented a
+	// group and its members from following the same dossier
+	if db.Migrator().HasIndex(&Subscription{}, "idx_subscriptions_decree_number") {
+		if err := db.Migrator().DropIndex(&Subscription{}, "idx_subscriptions_decree_number"); err != nil {
+			return nil, err
+		}
+	}
+	db.AutoMigrate(&Subscription{}, &Chat{}, &Broadcast{}, &Profile{}, &PendingNotification{}, &Lookup{}, &LookupCount{}, &Revision{}, &RevisionChange{}, &RevisionCursor{}, &ArchivedRevision{}, &ArchivedObservation{})
+
+	// Chats that subscribed before the chat registry existed are known too
+	err = db.Exec("INSERT OR IGNORE INTO chats (chat_id, first_seen, last_seen, blocked) SELECT DISTINCT chat_id, ?, ?, ? FROM subscriptions", time.Now(), time.Now(), false).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Changes used to store orders as printed, some with leading zeros
+	err = db.Exec("UPDATE revision_changes SET `order` = ltrim(`order`, '0') WHERE `order` LIKE '0%' AND ltrim(`order`, '0') NOT LIKE '/%'").Error
+	if err != nil {
+		return nil, err
+	}
 	return db, nil
 }
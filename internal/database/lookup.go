@@ -0,0 +1,30 @@
+package database
+
+import "time"
+
+// MaxLookupsPerChat is the number of lookups kept in a chat's history
+const MaxLookupsPerChat = 50
+
+// Lookup is a dossier lookup made by a chat. State holds the decree.FindState
+// found and Revision the hash of the PDF it was found in.
+type Lookup struct {
+	ID           uint  `gorm:"primaryKey"`
+	ChatID       int64 `gorm:"index"`
+	DecreeNumber string
+	State        int
+	Revision     string
+	CreatedAt    time.Time `gorm:"index"`
+}
+
+// LookupCount is the number of lookups made on a day, across all chats. It
+// is kept apart from the history, which is capped per chat, erased on
+// request and purged, so the statistics do not shrink with it.
+type LookupCount struct {
+	Day   string `gorm:"primaryKey"`
+	Count int64
+}
+
+// lookupDay is the key of the day t falls on in the server's time zone
+func lookupDay(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
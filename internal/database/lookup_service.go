@@ -0,0 +1,66 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LookupService interface {
+	RecordLookups(lookups []Lookup) error
+	GetRecentLookups(chatID int64, limit int) ([]Lookup, error)
+	// CountLookupsOn returns the number of lookups made on the day of t
+	CountLookupsOn(t time.Time) (int64, error)
+}
+
+type lookupService struct {
+	db *gorm.DB
+}
+
+func NewLookupService(db *gorm.DB) LookupService {
+	return &lookupService{db: db}
+}
+
+// RecordLookups stores lookups made by a chat and drops the oldest ones
+// beyond MaxLookupsPerChat. The lookups are added to the day's count too.
+func (s *lookupService) RecordLookups(lookups []Lookup) error {
+	if len(lookups) == 0 {
+		return nil
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&lookups).Error; err != nil {
+			return err
+		}
+
+		count := LookupCount{Day: lookupDay(time.Now()), Count: int64(len(lookups))}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "day"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"count": gorm.Expr("lookup_counts.count + ?", count.Count)}),
+		}).Create(&count).Error
+		if err != nil {
+			return err
+		}
+
+		chatID := lookups[0].ChatID
+		keep := tx.Model(&Lookup{}).Select("id").Where("chat_id = ?", chatID).Order("id DESC").Limit(MaxLookupsPerChat)
+		return tx.Where("chat_id = ? AND id NOT IN (?)", chatID, keep).Delete(&Lookup{}).Error
+	})
+}
+
+// GetRecentLookups returns a chat's most recent lookups, newest first
+func (s *lookupService) GetRecentLookups(chatID int64, limit int) ([]Lookup, error) {
+	var lookups []Lookup
+	err := s.db.Where("chat_id = ?", chatID).Order("id DESC").Limit(limit).Find(&lookups).Error
+	if err != nil {
+		return nil, err
+	}
+	return lookups, nil
+}
+
+func (s *lookupService) CountLookupsOn(t time.Time) (int64, error) {
+	var count LookupCount
+	err := s.db.Where("day = ?", lookupDay(t)).Limit(1).Find(&count).Error
+	return count.Count, err
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestLookupCountOutlivesHistory(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Lookup{}, &LookupCount{}); err != nil {
+		t.Fatal(err)
+	}
+	s := NewLookupService(db)
+
+	for i := 0; i < 30; i++ {
+		batch := []Lookup{{ChatID: 1, DecreeNumber: "1/RD/2023"}, {ChatID: 1, DecreeNumber: "2/RD/2023"}}
+		if err := s.RecordLookups(batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.RecordLookups([]Lookup{{ChatID: 2, DecreeNumber: "3/RD/2023"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var kept int64
+	db.Model(&Lookup{}).Where("chat_id = ?", 1).Count(&kept)
+	if kept != MaxLookupsPerChat {
+		t.Errorf("kept %d lookups for the chat, want %d", kept, MaxLookupsPerChat)
+	}
+
+	// Erasing the history does not change the statistics
+	if err := db.Where("chat_id = ?", 1).Delete(&Lookup{}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count, err := s.CountLookupsOn(time.Now()); err != nil || count != 61 {
+		t.Errorf("CountLookupsOn(today) = %d, %v, want 61", count, err)
+	}
+	if count, err := s.CountLookupsOn(time.Now().AddDate(0, 0, -1)); err != nil || count != 0 {
+		t.Errorf("CountLookupsOn(yesterday) = %d, %v, want 0", count, err)
+	}
+}
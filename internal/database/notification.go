@@ -0,0 +1,13 @@
+package database
+
+import "time"
+
+// PendingNotification is a notification held back by a chat's quiet hours
+// or daily digest, to be delivered at DeliverAt
+type PendingNotification struct {
+	ID        uint  `gorm:"primaryKey"`
+	ChatID    int64 `gorm:"index"`
+	Text      string
+	DeliverAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
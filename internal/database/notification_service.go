@@ -0,0 +1,46 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationService interface {
+	QueueNotification(chatID int64, text string, deliverAt time.Time) error
+	GetDueNotifications(now time.Time) ([]PendingNotification, error)
+	DeleteNotifications(ids []uint) error
+}
+
+type notificationService struct {
+	db *gorm.DB
+}
+
+func NewNotificationService(db *gorm.DB) NotificationService {
+	return &notificationService{db: db}
+}
+
+func (s *notificationService) QueueNotification(chatID int64, text string, deliverAt time.Time) error {
+	notification := PendingNotification{
+		ChatID:    chatID,
+		Text:      text,
+		DeliverAt: deliverAt,
+	}
+	return s.db.Create(&notification).Error
+}
+
+// GetDueNotifications returns the notifications to deliver by now, oldest first
+func (s *notificationService) GetDueNotifications(now time.Time) ([]PendingNotification, error) {
+	var notifications []PendingNotification
+	if err := s.db.Where("deliver_at <= ?", now).Order("chat_id, created_at").Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (s *notificationService) DeleteNotifications(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.db.Delete(&PendingNotification{}, ids).Error
+}
@@ -0,0 +1,22 @@
+package database
+
+import "time"
+
+// ChatData is everything stored about a chat, as exported by /datele_mele
+type ChatData struct {
+	ChatID               int64                 `json:"chat_id"`
+	ExportedAt           time.Time             `json:"exported_at"`
+	Chat                 *Chat                 `json:"chat"`
+	Profile              *Profile              `json:"profile"`
+	Subscriptions        []Subscription        `json:"subscriptions"`
+	PendingNotifications []PendingNotification `json:"pending_notifications"`
+	Lookups              []Lookup              `json:"lookups"`
+	Broadcasts           []Broadcast           `json:"broadcasts"`
+}
+
+// PurgeReport counts the rows removed by a retention run
+type PurgeReport struct {
+	Chats         int
+	Notifications int64
+	Lookups       int64
+}
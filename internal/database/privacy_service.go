@@ -0,0 +1,131 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PrivacyService exports and erases the personal data stored about a chat.
+// Every table holding per-chat rows must be covered here.
+type PrivacyService interface {
+	ExportChatData(chatID int64) (*ChatData, error)
+	EraseChatData(chatID int64) error
+	PurgeExpired(before time.Time) (PurgeReport, error)
+}
+
+type privacyService struct {
+	db *gorm.DB
+}
+
+func NewPrivacyService(db *gorm.DB) PrivacyService {
+	return &privacyService{db: db}
+}
+
+func (s *privacyService) ExportChatData(chatID int64) (*ChatData, error) {
+	data := ChatData{
+		ChatID:     chatID,
+		ExportedAt: time.Now(),
+	}
+
+	var chat Chat
+	if err := s.db.First(&chat, "chat_id = ?", chatID).Error; err == nil {
+		data.Chat = &chat
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var profile Profile
+	if err := s.db.First(&profile, "chat_id = ?", chatID).Error; err == nil {
+		data.Profile = &profile
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := s.db.Where("chat_id = ?", chatID).Find(&data.Subscriptions).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("chat_id = ?", chatID).Find(&data.PendingNotifications).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("chat_id = ?", chatID).Order("id").Find(&data.Lookups).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("created_by = ?", chatID).Find(&data.Broadcasts).Error; err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+// EraseChatData removes every row stored about a chat in a single transaction
+func (s *privacyService) EraseChatData(chatID int64) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return eraseChat(tx, chatID)
+	})
+}
+
+// PurgeExpired erases chats inactive since before that no longer follow any
+// dossier, notifications queued before then that were never delivered and
+// lookup history older than before
+func (s *privacyService) PurgeExpired(before time.Time) (PurgeReport, error) {
+	var report PurgeReport
+
+	var chatIDs []int64
+	err := s.db.Raw(`SELECT chat_id FROM chats WHERE last_seen < ?
+		UNION SELECT chat_id FROM profiles WHERE last_active_at < ? AND chat_id NOT IN (SELECT chat_id FROM chats)`, before, before).
+		Scan(&chatIDs).Error
+	if err != nil {
+		return report, err
+	}
+
+	for _, chatID := range chatIDs {
+		var subscriptions int64
+		if err := s.db.Model(&Subscription{}).Where("chat_id = ?", chatID).Count(&subscriptions).Error; err != nil {
+			return report, err
+		}
+		// Chats waiting for a dossier are still using the bot even if they never write
+		if subscriptions > 0 {
+			continue
+		}
+		if err := s.EraseChatData(chatID); err != nil {
+			return report, err
+		}
+		report.Chats++
+	}
+
+	result := s.db.Where("created_at < ?", before).Delete(&PendingNotification{})
+	if result.Error != nil {
+		return report, result.Error
+	}
+	report.Notifications = result.RowsAffected
+
+	result = s.db.Where("created_at < ?", before).Delete(&Lookup{})
+	if result.Error != nil {
+		return report, result.Error
+	}
+	report.Lookups = result.RowsAffected
+
+	return report, nil
+}
+
+func eraseChat(tx *gorm.DB, chatID int64) error {
+	deletions := []struct {
+		model interface{}
+		query string
+	}{
+		{&Subscription{}, "chat_id = ?"},
+		{&PendingNotification{}, "chat_id = ?"},
+		{&Lookup{}, "chat_id = ?"},
+		{&Broadcast{}, "created_by = ?"},
+		{&Profile{}, "chat_id = ?"},
+		{&Chat{}, "chat_id = ?"},
+	}
+	for _, deletion := range deletions {
+		if err := tx.Where(deletion.query, chatID).Delete(deletion.model).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
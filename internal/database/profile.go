@@ -0,0 +1,55 @@
+package database
+
+import (
+	"time"
+	// Embed the timezone database so preferences work on hosts without tzdata
+	_ "time/tzdata"
+)
+
+// DefaultTimezone is used for chats that did not choose a timezone
+const DefaultTimezone = "Europe/Bucharest"
+
+// Default notification schedule, in hours of the chat's local time
+const (
+	DefaultQuietStart = 22
+	DefaultQuietEnd   = 8
+	DefaultDigestHour = 9
+)
+
+// Profile holds the Telegram details and preferences of a chat. For groups
+// the user fields are empty and Title holds the group name. LanguageCode is
+// the language of the user's Telegram client, kept as a Telegram detail: the
+// bot only answers in Romanian, so there is no language preference.
+type Profile struct {
+	ChatID               int64 `gorm:"primaryKey;autoIncrement:false"`
+	Username             string
+	FirstName            string
+	LastName             string
+	Title                string
+	LanguageCode         string
+	Timezone             string
+	NotificationsEnabled bool
+	QuietHoursEnabled    bool
+	QuietStart           int `gorm:"default:22"`
+	QuietEnd             int `gorm:"default:8"`
+	DigestEnabled        bool
+	DigestHour           int `gorm:"default:9"`
+	ConsentGiven         bool
+	ConsentAt            *time.Time
+	CreatedAt            time.Time
+	LastActiveAt         time.Time `gorm:"index"`
+}
+
+// Location returns the profile's timezone, falling back to DefaultTimezone
+func (p *Profile) Location() *time.Location {
+	if p.Timezone != "" {
+		if loc, err := time.LoadLocation(p.Timezone); err == nil {
+			return loc
+		}
+	}
+	loc, err := time.LoadLocation(DefaultTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProfileService interface {
+	TouchProfile(profile Profile) error
+	GetProfile(chatID int64) (*Profile, error)
+	SetTimezone(chatID int64, timezone string) error
+	SetNotifications(chatID int64, enabled bool) error
+	SetConsent(chatID int64, given bool) error
+	SetQuietHours(chatID int64, enabled bool, start, end int) error
+	SetDigest(chatID int64, enabled bool, hour int) error
+}
+
+type profileService struct {
+	db *gorm.DB
+}
+
+func NewProfileService(db *gorm.DB) ProfileService {
+	return &profileService{db: db}
+}
+
+// TouchProfile refreshes the Telegram details and activity time of a chat,
+// creating its profile with default preferences on first contact
+func (s *profileService) TouchProfile(profile Profile) error {
+	setDefaults(&profile)
+
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "chat_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"username", "first_name", "last_name", "title", "language_code", "last_active_at"}),
+	}).Create(&profile).Error
+}
+
+// GetProfile returns the profile of a chat, or the default preferences if
+// the chat has no profile yet
+func (s *profileService) GetProfile(chatID int64) (*Profile, error) {
+	var profile Profile
+	err := s.db.First(&profile, "chat_id = ?", chatID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		profile = Profile{ChatID: chatID}
+		setDefaults(&profile)
+		return &profile, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (s *profileService) SetTimezone(chatID int64, timezone string) error {
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("fus orar invalid %q: %w", timezone, err)
+	}
+	return s.update(chatID, map[string]interface{}{"timezone": timezone})
+}
+
+func (s *profileService) SetNotifications(chatID int64, enabled bool) error {
+	return s.update(chatID, map[string]interface{}{"notifications_enabled": enabled})
+}
+
+func (s *profileService) SetConsent(chatID int64, given bool) error {
+	var consentAt *time.Time
+	if given {
+		now := time.Now()
+		consentAt = &now
+	}
+	return s.update(chatID, map[string]interface{}{"consent_given": given, "consent_at": consentAt})
+}
+
+// SetQuietHours sets the hours, in the chat's timezone, during which
+// notifications are held back. The window may span midnight (e.g. 22 to 8).
+func (s *profileService) SetQuietHours(chatID int64, enabled bool, start, end int) error {
+	if !validHour(start) || !validHour(end) || start == end {
+		return fmt.Errorf("interval de liniște invalid: %d-%d", start, end)
+	}
+	return s.update(chatID, map[string]interface{}{"quiet_hours_enabled": enabled, "quiet_start": start, "quiet_end": end})
+}
+
+// SetDigest sets whether notifications are gathered into a single daily
+// message delivered at the given hour of the chat's timezone
+func (s *profileService) SetDigest(chatID int64, enabled bool, hour int) error {
+	if !validHour(hour) {
+		return fmt.Errorf("oră invalidă pentru rezumat: %d", hour)
+	}
+	return s.update(chatID, map[string]interface{}{"digest_enabled": enabled, "digest_hour": hour})
+}
+
+// update changes preference columns, creating the profile first if needed
+func (s *profileService) update(chatID int64, fields map[string]interface{}) error {
+	profile := Profile{ChatID: chatID}
+	setDefaults(&profile)
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&profile).Error; err != nil {
+		return err
+	}
+	return s.db.Model(&Profile{}).Where("chat_id = ?", chatID).Updates(fields).Error
+}
+
+func setDefaults(profile *Profile) {
+	now := time.Now()
+	profile.Timezone = DefaultTimezone
+	profile.NotificationsEnabled = true
+	profile.QuietStart = DefaultQuietStart
+	profile.QuietEnd = DefaultQuietEnd
+	profile.DigestHour = DefaultDigestHour
+	profile.CreatedAt = now
+	profile.LastActiveAt = now
+}
+
+func validHour(hour int) bool {
+	return hour >= 0 && hour <= 23
+}
@@ -0,0 +1,62 @@
+package database
+
+import "time"
+
+// Kinds of RevisionChange
+const (
+	ChangeAdded    = "added"
+	ChangeResolved = "resolved"
+	ChangeRemoved  = "removed"
+	ChangeChanged  = "changed"
+)
+
+// Revision is a parsed revision of an annual PDF. The counts describe the
+// changes against the previous revision of the same year; the first revision
+// of a year is a baseline without changes. Snapshot holds the encoded rows
+// and is kept only for the latest revision of each year. AnnouncedAt is set
+// once the revision was published to the channel feed.
+type Revision struct {
+	ID           uint `gorm:"primaryKey"`
+	Year         int  `gorm:"index"`
+	Hash         string
+	PreviousHash string
+	Entries      int
+	// Resolved counts the dossiers resolved in the revision
+	Resolved      int
+	Added         int
+	NewlyResolved int
+	Removed       int
+	Changed       int
+	// HighestResolved is the highest dossier number resolved in the revision
+	HighestResolved int
+	Snapshot        []byte
+	AnnouncedAt     *time.Time
+	CreatedAt       time.Time
+}
+
+// Baseline reports whether the revision is the first one seen for its year
+func (r Revision) Baseline() bool {
+	return r.PreviousHash == ""
+}
+
+// RevisionChange is a row that differs between a revision and the previous
+// one. Solutions are empty on the side where the row is not listed.
+type RevisionChange struct {
+	ID           uint   `gorm:"primaryKey"`
+	RevisionID   uint   `gorm:"index"`
+	Kind         string `gorm:"index"`
+	DecreeNumber string `gorm:"index"`
+	Order        string
+	OldSolution  string
+	NewSolution  string
+}
+
+// RevisionCursor is the last revision of a year a consumer, such as the
+// subscription checker, has processed. Consumers track revisions at their own
+// pace, the cursor tells each one what it has not seen yet.
+type RevisionCursor struct {
+	Consumer   string `gorm:"primaryKey"`
+	Year       int    `gorm:"primaryKey;autoIncrement:false"`
+	RevisionID uint
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,211 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// revisionChangeBatch bounds the rows inserted per statement, sqlite limits
+// the number of variables a statement may bind
+const revisionChangeBatch = 500
+
+type RevisionService interface {
+	GetLatestRevision(year int) (*Revision, error)
+	GetRevisionBefore(year int, before time.Time) (*Revision, error)
+	GetRevision(id uint) (*Revision, error)
+	GetRecentRevisions(limit int) ([]Revision, error)
+	GetRevisionsAfter(year int, afterID uint) ([]Revision, error)
+	GetChanges(revisionID uint) ([]RevisionChange, error)
+	SaveRevision(revision *Revision, changes []RevisionChange) error
+	GetUnannouncedRevisions() ([]Revision, error)
+	MarkAnnounced(ids []uint) error
+	GetResolutionDates(year int) (map[string]time.Time, error)
+	GetOrderFirstSeen(order string) (*time.Time, error)
+	GetCursor(consumer string, year int) (*RevisionCursor, error)
+	SetCursor(consumer string, year int, revisionID uint) error
+}
+
+type revisionService struct {
+	db *gorm.DB
+}
+
+func NewRevisionService(db *gorm.DB) RevisionService {
+	return &revisionService{db: db}
+}
+
+// GetLatestRevision returns the newest revision of a year, or nil if none was recorded
+func (s *revisionService) GetLatestRevision(year int) (*Revision, error) {
+	var revision Revision
+	err := s.db.Where("year = ?", year).Order("id DESC").First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// GetRevisionBefore returns the last revision of a year recorded before the
+// given time, or nil if none was
+func (s *revisionService) GetRevisionBefore(year int, before time.Time) (*Revision, error) {
+	var revision Revision
+	err := s.db.Omit("snapshot").Where("year = ? AND created_at < ?", year, before).Order("id DESC").First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+func (s *revisionService) GetRevision(id uint) (*Revision, error) {
+	var revision Revision
+	if err := s.db.Omit("snapshot").First(&revision, id).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// GetRecentRevisions returns the newest revisions of every year, newest first
+func (s *revisionService) GetRecentRevisions(limit int) ([]Revision, error) {
+	var revisions []Revision
+	err := s.db.Omit("snapshot").Order("id DESC").Limit(limit).Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetRevisionsAfter returns the revisions of a year recorded after the given
+// one, oldest first
+func (s *revisionService) GetRevisionsAfter(year int, afterID uint) ([]Revision, error) {
+	var revisions []Revision
+	err := s.db.Omit("snapshot").Where("year = ? AND id > ?", year, afterID).Order("id").Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+// GetChanges returns the changes of a revision ordered by kind and dossier
+func (s *revisionService) GetChanges(revisionID uint) ([]RevisionChange, error) {
+	var changes []RevisionChange
+	err := s.db.Where("revision_id = ?", revisionID).Order("kind, id").Find(&changes).Error
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// SaveRevision stores a revision with its changes and drops the snapshots of
+// the older revisions of the same year
+func (s *revisionService) SaveRevision(revision *Revision, changes []RevisionChange) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(revision).Error; err != nil {
+			return err
+		}
+
+		if len(changes) > 0 {
+			for i := range changes {
+				changes[i].RevisionID = revision.ID
+			}
+			if err := tx.CreateInBatches(changes, revisionChangeBatch).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&Revision{}).
+			Where("year = ? AND id <> ?", revision.Year, revision.ID).
+			Update("snapshot", nil).Error
+	})
+}
+
+// GetUnannouncedRevisions returns the revisions not yet published to the
+// channel feed, oldest first
+func (s *revisionService) GetUnannouncedRevisions() ([]Revision, error) {
+	var revisions []Revision
+	err := s.db.Omit("snapshot").Where("announced_at IS NULL").Order("id").Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (s *revisionService) MarkAnnounced(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.db.Model(&Revision{}).Where("id IN ?", ids).Update("announced_at", time.Now()).Error
+}
+
+// GetResolutionDates returns, for every dossier of a year that gained an
+// order between two recorded revisions, when the revision listing it was recorded
+func (s *revisionService) GetResolutionDates(year int) (map[string]time.Time, error) {
+	var rows []struct {
+		DecreeNumber string
+		CreatedAt    time.Time
+	}
+	err := s.db.Table("revision_changes").
+		Select("revision_changes.decree_number, revisions.created_at").
+		Joins("JOIN revisions ON revisions.id = revision_changes.revision_id").
+		Where("revisions.year = ? AND revision_changes.kind = ?", year, ChangeResolved).
+		Order("revisions.id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	dates := make(map[string]time.Time, len(rows))
+	for _, row := range rows {
+		// A dossier can be resolved again after being removed, the first time counts
+		if _, ok := dates[row.DecreeNumber]; !ok {
+			dates[row.DecreeNumber] = row.CreatedAt
+		}
+	}
+	return dates, nil
+}
+
+// GetOrderFirstSeen returns when a recorded revision first listed an order,
+// given in its canonical form, or nil if no recorded change mentions it
+func (s *revisionService) GetOrderFirstSeen(order string) (*time.Time, error) {
+	var revision Revision
+	err := s.db.Omit("snapshot").
+		Joins("JOIN revision_changes ON revision_changes.revision_id = revisions.id").
+		Where("revision_changes.`order` = ? AND revision_changes.kind IN ?", order, []string{ChangeAdded, ChangeResolved}).
+		Order("revisions.id").
+		First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &revision.CreatedAt, nil
+}
+
+// GetCursor returns the last revision of a year the consumer processed, or
+// nil if it never processed one
+func (s *revisionService) GetCursor(consumer string, year int) (*RevisionCursor, error) {
+	var cursor RevisionCursor
+	err := s.db.Where("consumer = ? AND year = ?", consumer, year).First(&cursor).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+func (s *revisionService) SetCursor(consumer string, year int, revisionID uint) error {
+	cursor := RevisionCursor{Consumer: consumer, Year: year, RevisionID: revisionID, UpdatedAt: time.Now()}
+	return s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "consumer"}, {Name: "year"}},
+		DoUpdates: clause.AssignmentColumns([]string{"revision_id", "updated_at"}),
+	}).Create(&cursor).Error
+}
@@ -1,17 +1,29 @@
 package database
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// ErrSubscriptionNotFound is returned when changing a subscription the chat does not have
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
 type SubscriptionService interface {
-	CreateSubscription(chatID int64, decreeNumber string) error
+	CreateSubscription(chatID int64, decreeNumber, label string) error
+	CreateRangeSubscription(subscription Subscription) error
 	DeleteSubscription(chatID int64, decreeNumber string) error
 	DeleteAllSubscriptions(chatID int64) error
-	GetSubscriptions(chatID int64) ([]string, error)
+	GetSubscriptions(chatID int64) ([]Subscription, error)
+	SetLabel(chatID int64, decreeNumber, label string) error
+	SetNote(chatID int64, decreeNumber, note string) error
+	SetLastState(chatID int64, decreeNumber string, state int) error
+	SetOathAppointment(chatID int64, decreeNumber string, state int, date time.Time) error
+	SetResolvedSnapshot(id uint, snapshot []byte) error
 	GetAllSubscriptions() ([]Subscription, error)
+	MigrateChat(oldChatID, newChatID int64) error
 }
 
 type subscriptionService struct {
@@ -22,21 +34,35 @@ func NewSubscriptionService(db *gorm.DB) SubscriptionService {
 	return &subscriptionService{db: db}
 }
 
-func (s *subscriptionService) CreateSubscription(chatID int64, decreeNumber string) error {
+func (s *subscriptionService) CreateSubscription(chatID int64, decreeNumber, label string) error {
+	return s.create(Subscription{
+		ChatID:       chatID,
+		DecreeNumber: decreeNumber,
+		Label:        label,
+	})
+}
+
+// CreateRangeSubscription stores a range or year subscription; its
+// DecreeNumber must hold the canonical form of the range
+func (s *subscriptionService) CreateRangeSubscription(subscription Subscription) error {
+	if !subscription.IsRange() {
+		return fmt.Errorf("invalid range subscription kind %q", subscription.Kind)
+	}
+	subscription.ResolvedSnapshot = nil
+	return s.create(subscription)
+}
+
+func (s *subscriptionService) create(subscription Subscription) error {
 	// Check if subscription already exists
 	var existingSubscription Subscription
-	result := s.db.Where("chat_id = ? AND decree_number = ?", chatID, decreeNumber).First(&existingSubscription)
+	result := s.db.Where("chat_id = ? AND decree_number = ?", subscription.ChatID, subscription.DecreeNumber).First(&existingSubscription)
 	if result.Error == nil {
-		return fmt.Errorf("subscription already exists for decree number %s", decreeNumber)
+		return fmt.Errorf("subscription already exists for decree number %s", subscription.DecreeNumber)
 	}
 	if result.Error != gorm.ErrRecordNotFound {
 		return fmt.Errorf("error checking existing subscription: %v", result.Error)
 	}
 
-	subscription := Subscription{
-		ChatID:       chatID,
-		DecreeNumber: decreeNumber,
-	}
 	return s.db.Create(&subscription).Error
 }
 
@@ -51,17 +77,50 @@ func (s *subscriptionService) DeleteAllSubscriptions(chatID int64) error {
 	return s.db.Where("chat_id = ?", chatID).Delete(&subscription).Error
 }
 
-func (s *subscriptionService) GetSubscriptions(chatID int64) ([]string, error) {
+func (s *subscriptionService) GetSubscriptions(chatID int64) ([]Subscription, error) {
 	var subscriptions []Subscription
 	err := s.db.Where("chat_id = ?", chatID).Find(&subscriptions).Error
 	if err != nil {
 		return nil, err
 	}
-	var decreeNumbers []string
-	for _, subscription := range subscriptions {
-		decreeNumbers = append(decreeNumbers, subscription.DecreeNumber)
+	return subscriptions, nil
+}
+
+func (s *subscriptionService) SetLabel(chatID int64, decreeNumber, label string) error {
+	return s.updateSubscription(chatID, decreeNumber, "label", label)
+}
+
+func (s *subscriptionService) SetNote(chatID int64, decreeNumber, note string) error {
+	return s.updateSubscription(chatID, decreeNumber, "note", note)
+}
+
+// SetLastState records the state found by a check of the subscription
+func (s *subscriptionService) SetLastState(chatID int64, decreeNumber string, state int) error {
+	return s.db.Model(&Subscription{}).Where("chat_id = ? AND decree_number = ?", chatID, decreeNumber).
+		Updates(map[string]interface{}{"last_state": state, "last_checked_at": time.Now()}).Error
+}
+
+// SetOathAppointment records the state of a subscription together with the
+// oath ceremony its chat was told about
+func (s *subscriptionService) SetOathAppointment(chatID int64, decreeNumber string, state int, date time.Time) error {
+	return s.db.Model(&Subscription{}).Where("chat_id = ? AND decree_number = ?", chatID, decreeNumber).
+		Updates(map[string]interface{}{"last_state": state, "oath_date": date, "last_checked_at": time.Now()}).Error
+}
+
+// SetResolvedSnapshot records the numbers of a range subscription found resolved
+func (s *subscriptionService) SetResolvedSnapshot(id uint, snapshot []byte) error {
+	return s.db.Model(&Subscription{}).Where("id = ?", id).Update("resolved_snapshot", snapshot).Error
+}
+
+func (s *subscriptionService) updateSubscription(chatID int64, decreeNumber, column, value string) error {
+	result := s.db.Model(&Subscription{}).Where("chat_id = ? AND decree_number = ?", chatID, decreeNumber).Update(column, value)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSubscriptionNotFound
 	}
-	return decreeNumbers, nil
+	return nil
 }
 
 func (s *subscriptionService) GetAllSubscriptions() ([]Subscription, error) {
@@ -72,3 +131,42 @@ func (s *subscriptionService) GetAllSubscriptions() ([]Subscription, error) {
 	}
 	return subscriptions, nil
 }
+
+// MigrateChat moves everything stored for a chat to a new chat ID, as
+// happens when a group is upgraded to a supergroup. The supergroup may have
+// been registered already by its first update: the group's rows, which hold
+// its settings and history, replace it.
+func (s *subscriptionService) MigrateChat(oldChatID, newChatID int64) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Subscriptions already made in the supergroup are kept
+		duplicates := tx.Model(&Subscription{}).Select("decree_number").Where("chat_id = ?", newChatID)
+		err := tx.Where("chat_id = ? AND decree_number IN (?)", oldChatID, duplicates).Delete(&Subscription{}).Error
+		if err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{&Profile{}, &Chat{}} {
+			var count int64
+			if err := tx.Model(model).Where("chat_id = ?", oldChatID).Count(&count).Error; err != nil {
+				return err
+			}
+			if count == 0 {
+				continue
+			}
+			if err := tx.Where("chat_id = ?", newChatID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, model := range []interface{}{&Subscription{}, &PendingNotification{}, &Lookup{}, &Profile{}} {
+			if err := tx.Model(model).Where("chat_id = ?", oldChatID).Update("chat_id", newChatID).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&Chat{}).Where("chat_id = ?", oldChatID).Updates(map[string]interface{}{
+			"chat_id": newChatID,
+			"type":    "supergroup",
+			"blocked": false,
+		}).Error
+	})
+}
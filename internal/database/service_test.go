@@ -0,0 +1,76 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMigrateChat(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&Subscription{}, &Chat{}, &Profile{}, &PendingNotification{}, &Lookup{}); err != nil {
+		t.Fatal(err)
+	}
+
+	const group, supergroup = -100, -1000000000100
+	firstSeen := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []interface{}{
+		&Subscription{ChatID: group, DecreeNumber: "1/RD/2023", Label: "Mama"},
+		&Subscription{ChatID: group, DecreeNumber: "2/RD/2023"},
+		&PendingNotification{ChatID: group, Text: "rezolvat"},
+		&Lookup{ChatID: group, DecreeNumber: "1/RD/2023"},
+		&Profile{ChatID: group, Title: "Familia", Timezone: "Europe/Chisinau"},
+		&Chat{ChatID: group, Type: "group", FirstSeen: firstSeen},
+		// The supergroup's first update registered it before the migration
+		&Profile{ChatID: supergroup, Title: "Familia"},
+		&Chat{ChatID: supergroup, Type: "supergroup"},
+		&Subscription{ChatID: supergroup, DecreeNumber: "2/RD/2023", Label: "Tata"},
+		// Other chats are left alone
+		&Subscription{ChatID: 7, DecreeNumber: "1/RD/2023"},
+	}
+	for _, row := range rows {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := NewSubscriptionService(db).MigrateChat(group, supergroup); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, model := range []interface{}{&Subscription{}, &PendingNotification{}, &Lookup{}, &Profile{}, &Chat{}} {
+		var left int64
+		db.Model(model).Where("chat_id = ?", group).Count(&left)
+		if left != 0 {
+			t.Errorf("%T: %d rows left for the group", model, left)
+		}
+	}
+
+	var subscriptions []Subscription
+	db.Where("chat_id = ?", supergroup).Order("decree_number").Find(&subscriptions)
+	if len(subscriptions) != 2 || subscriptions[0].Label != "Mama" || subscriptions[1].Label != "Tata" {
+		t.Errorf("supergroup subscriptions = %+v", subscriptions)
+	}
+
+	var notifications, lookups, others int64
+	db.Model(&PendingNotification{}).Where("chat_id = ?", supergroup).Count(&notifications)
+	db.Model(&Lookup{}).Where("chat_id = ?", supergroup).Count(&lookups)
+	db.Model(&Subscription{}).Where("chat_id = ?", 7).Count(&others)
+	if notifications != 1 || lookups != 1 || others != 1 {
+		t.Errorf("notifications %d, lookups %d, other chat %d, want 1 each", notifications, lookups, others)
+	}
+
+	var profile Profile
+	if err := db.First(&profile, "chat_id = ?", supergroup).Error; err != nil || profile.Timezone != "Europe/Chisinau" {
+		t.Errorf("supergroup profile = %+v, %v, want the group's settings", profile, err)
+	}
+	var chat Chat
+	if err := db.First(&chat, "chat_id = ?", supergroup).Error; err != nil || chat.Type != "supergroup" || !chat.FirstSeen.Equal(firstSeen) {
+		t.Errorf("supergroup chat = %+v, %v", chat, err)
+	}
+}
@@ -1,7 +1,56 @@
 package database
 
+import (
+	"fmt"
+	"html"
+	"time"
+)
+
 type Subscription struct {
-	ID           uint `gorm:"primaryKey"`
-	ChatID       int64
-	DecreeNumber string `gorm:"uniqueIndex"`
+	ID           uint   `gorm:"primaryKey"`
+	ChatID       int64  `gorm:"uniqueIndex:idx_subscriptions_chat_decree"`
+	DecreeNumber string `gorm:"uniqueIndex:idx_subscriptions_chat_decree"`
+	// Label and Note are set by the user to tell their dossiers apart
+	Label string
+	Note  string
+	// LastState is the decree.FindState found by the last check, if any
+	LastState     *int
+	LastCheckedAt *time.Time
+	// OathDate is the oath ceremony the chat was told about, once the
+	// dossier is listed in an oath scheduling document
+	OathDate *time.Time
+	// Kind is empty for a single dossier. Range and year subscriptions watch
+	// the numbers RangeFrom to RangeTo of RangeYear, and DecreeNumber holds
+	// their canonical form (e.g. 1000-1500/RD/2022 or */RD/2022).
+	Kind      string
+	RangeYear int
+	RangeFrom int
+	RangeTo   int
+	// ResolvedSnapshot has one bit per number of the range, set for the
+	// numbers already resolved at the last check; nil before the first check
+	ResolvedSnapshot []byte
+}
+
+// Subscription kinds
+const (
+	SubscriptionRange = "range"
+	SubscriptionYear  = "year"
+)
+
+// IsRange reports whether the subscription watches a range or a whole year
+func (s Subscription) IsRange() bool {
+	return s.Kind == SubscriptionRange || s.Kind == SubscriptionYear
+}
+
+// Title shows the dossier number with the label the user gave it, as
+// Telegram HTML, wherever a subscription is named to its chat
+func (s Subscription) Title() string {
+	text := fmt.Sprintf("<code>%s</code>", s.DecreeNumber)
+	if s.Kind == SubscriptionYear {
+		text += fmt.Sprintf(" (toate dosarele din %d)", s.RangeYear)
+	}
+	if s.Label != "" {
+		text += fmt.Sprintf(" — 🏷 <b>%s</b>", html.EscapeString(s.Label))
+	}
+	return text
 }
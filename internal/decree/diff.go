@@ -0,0 +1,64 @@
+package decree
+
+import "sort"
+
+// RevisionDiff lists the rows that differ between two revisions of the same
+// annual PDF
+type RevisionDiff struct {
+	// Added rows are listed only in the new revision
+	Added []Entry
+	// NewlyResolved rows gained a resolution order in the new revision
+	NewlyResolved []Entry
+	// Removed rows are listed only in the old revision
+	Removed []Entry
+	// Changed rows have a different solution without becoming resolved
+	Changed []EntryChange
+}
+
+// EntryChange is a row whose solution differs between two revisions
+type EntryChange struct {
+	Old Entry
+	New Entry
+}
+
+// Empty reports whether both revisions list the same rows
+func (d *RevisionDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.NewlyResolved) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Diff compares two parsed revisions of the same annual PDF. Every list is
+// sorted by dossier number.
+func Diff(old, new *Document) *RevisionDiff {
+	diff := &RevisionDiff{}
+
+	for number, entry := range new.Entries {
+		previous, ok := old.Entries[number]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, entry)
+		case previous.State() != StateFoundAndResolved && entry.State() == StateFoundAndResolved:
+			diff.NewlyResolved = append(diff.NewlyResolved, entry)
+		case previous.Solution != entry.Solution:
+			diff.Changed = append(diff.Changed, EntryChange{Old: previous, New: entry})
+		}
+	}
+	for number, entry := range old.Entries {
+		if _, ok := new.Entries[number]; !ok {
+			diff.Removed = append(diff.Removed, entry)
+		}
+	}
+
+	sortEntries(diff.Added)
+	sortEntries(diff.NewlyResolved)
+	sortEntries(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].New.Number.Value < diff.Changed[j].New.Number.Value
+	})
+	return diff
+}
+
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Number.Value < entries[j].Number.Value
+	})
+}
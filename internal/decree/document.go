@@ -0,0 +1,137 @@
+package decree
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+)
+
+var (
+	// rowPattern matches the canonical dossier identifiers printed in the annual PDFs
+	rowPattern = regexp.MustCompile(`(\d+)/RD/(\d{4})`)
+	// orderPattern matches a resolution order (ordin) such as 1234/P/2024
+	orderPattern = regexp.MustCompile(`\d+/P/\d{4}`)
+	// datePattern matches the date of an order, printed as dd.mm.yyyy
+	datePattern = regexp.MustCompile(`\d{2}\.\d{2}\.\d{4}`)
+
+	// Markers of the outcomes other than a resolution, e.g. "respins",
+	// "respingere", "restituire", "suspendat"
+	rejectedPattern  = regexp.MustCompile(`(?i)respin[sg]`)
+	returnedPattern  = regexp.MustCompile(`(?i)restitu`)
+	suspendedPattern = regexp.MustCompile(`(?i)suspend`)
+)
+
+// Entry is a single dossier row of an annual PDF
+type Entry struct {
+	Number   dossier.Number
+	Solution string
+	Order    string
+	// OrderDate is the date printed next to the order, zero if missing
+	OrderDate time.Time
+}
+
+// State returns the FindState of the row. Rejections, returns and
+// suspensions can be printed with an order number too, so their markers are
+// checked before the order.
+func (e Entry) State() FindState {
+	switch {
+	case rejectedPattern.MatchString(e.Solution):
+		return StateRejected
+	case returnedPattern.MatchString(e.Solution):
+		return StateReturned
+	case suspendedPattern.MatchString(e.Solution):
+		return StateSuspended
+	}
+	if strings.Contains(e.Solution, "/P/") {
+		return StateFoundAndResolved
+	}
+	return StateFoundButNotResolved
+}
+
+// Document is the parsed content of an annual PDF. Revision is the SHA-256
+// of the PDF it was parsed from, in hex.
+type Document struct {
+	Year     int
+	Revision string
+	Entries  map[dossier.Number]Entry
+
+	orders orderIndex
+}
+
+// add stores a parsed row. A dossier listed twice keeps the row that
+// carries an order, whichever page it was read from first.
+func (d *Document) add(entry Entry) {
+	if existing, ok := d.Entries[entry.Number]; ok && existing.Order != "" {
+		return
+	}
+	d.Entries[entry.Number] = entry
+}
+
+// Lookup returns the row for the given dossier, if it is listed
+func (d *Document) Lookup(number dossier.Number) (Entry, bool) {
+	entry, ok := d.Entries[number]
+	return entry, ok
+}
+
+// Resolved returns the numbers between from and to (inclusive) whose
+// dossiers are resolved in the document, in ascending order
+func (d *Document) Resolved(from, to int) []int {
+	var numbers []int
+	for number, entry := range d.Entries {
+		if number.Value >= from && number.Value <= to && entry.State() == StateFoundAndResolved {
+			numbers = append(numbers, number.Value)
+		}
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// State returns the FindState of the given dossier within the document
+func (d *Document) State(number dossier.Number) FindState {
+	entry, ok := d.Lookup(number)
+	if !ok {
+		return StateNotFound
+	}
+	return entry.State()
+}
+
+// parseEntries extracts every dossier row from the plain text of a page.
+// The solution of a row is the text following its identifier, up to OFFSET
+// characters or the next identifier, whichever comes first.
+func parseEntries(text string) []Entry {
+	indexes := rowPattern.FindAllStringSubmatchIndex(text, -1)
+	entries := make([]Entry, 0, len(indexes))
+
+	for i, idx := range indexes {
+		match, err := dossier.Parse(text[idx[0]:idx[1]])
+		if err != nil {
+			continue
+		}
+
+		end := min(idx[0]+OFFSET, len(text))
+		if i+1 < len(indexes) {
+			end = min(end, indexes[i+1][0])
+		}
+		// The window counts bytes, it must not split a character
+		for end > idx[1] && end < len(text) && !utf8.RuneStart(text[end]) {
+			end--
+		}
+		solution := strings.TrimSpace(text[idx[1]:end])
+
+		entry := Entry{
+			Number:   match.Number,
+			Solution: solution,
+			Order:    orderPattern.FindString(solution),
+		}
+		if date, err := time.Parse("02.01.2006", datePattern.FindString(solution)); err == nil {
+			entry.OrderDate = date
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
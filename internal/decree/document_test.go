@@ -0,0 +1,290 @@
+package decree
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/andiq123/cetatenie-analyzer/internal/fetcher"
+)
+
+// pageFixture is the plain text of a page as the PDF reader returns it: one
+// row per line, the table columns run together
+const pageFixture = "1/RD/2023 1234/P/2024 din 15.03.2024\n" +
+	"2/RD/2023\n" +
+	"3/RD/2023 respins prin 77/P/2024\n" +
+	"4/RD/2023 0000000000111111111122222222223333333333\n" +
+	"5/RD/2023 în procesare, țară șăîâ șăîâ șăîâ șăîâ\n" +
+	"6/RD/2023 7/RD/2023\n" +
+	"6/RD/2023 88/P/2024 din 01.02.2024\n"
+
+func TestParseEntries(t *testing.T) {
+	entries := parseEntries(pageFixture)
+
+	want := []struct {
+		value     int
+		solution  string
+		order     string
+		orderDate string
+		state     FindState
+	}{
+		{1, "1234/P/2024 din 15.03.2024", "1234/P/2024", "15.03.2024", StateFoundAndResolved},
+		// Cut off by the next identifier before the window ends
+		{2, "", "", "", StateFoundButNotResolved},
+		{3, "respins prin 77/P/2024", "77/P/2024", "", StateRejected},
+		// Cut off by the window: OFFSET characters counted from the identifier
+		{4, "000000000011111111112222222222333", "", "", StateFoundButNotResolved},
+		// The window counts bytes and stops before a split character
+		{5, "în procesare, țară șăîâ ș", "", "", StateFoundButNotResolved},
+		// Duplicate identifiers are returned as separate rows; the first one
+		// is cut off by the next identifier on its line
+		{6, "", "", "", StateFoundButNotResolved},
+		{7, "", "", "", StateFoundButNotResolved},
+		{6, "88/P/2024 din 01.02.2024", "88/P/2024", "01.02.2024", StateFoundAndResolved},
+	}
+
+	if len(entries) != len(want) {
+		t.Fatalf("parseEntries returned %d rows, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		entry := entries[i]
+		if entry.Number != (dossier.Number{Value: w.value, Year: 2023}) {
+			t.Errorf("row %d: number %s, want %d/RD/2023", i, entry.Number, w.value)
+		}
+		if !utf8.ValidString(entry.Solution) {
+			t.Errorf("row %d: solution %q is not valid UTF-8", i, entry.Solution)
+		}
+		if len(entry.Solution) > OFFSET {
+			t.Errorf("row %d: solution %q is longer than the window", i, entry.Solution)
+		}
+		if entry.Solution != w.solution {
+			t.Errorf("row %d: solution %q, want %q", i, entry.Solution, w.solution)
+		}
+		if entry.Order != w.order {
+			t.Errorf("row %d: order %q, want %q", i, entry.Order, w.order)
+		}
+		var orderDate string
+		if !entry.OrderDate.IsZero() {
+			orderDate = entry.OrderDate.Format("02.01.2006")
+		}
+		if orderDate != w.orderDate {
+			t.Errorf("row %d: order date %q, want %q", i, orderDate, w.orderDate)
+		}
+		if entry.State() != w.state {
+			t.Errorf("row %d: state %v, want %v", i, entry.State(), w.state)
+		}
+	}
+}
+
+func TestDocumentKeepsDuplicateWithOrder(t *testing.T) {
+	number := dossier.Number{Value: 6, Year: 2023}
+	pending := Entry{Number: number}
+	resolved := Entry{Number: number, Solution: "88/P/2024", Order: "88/P/2024"}
+
+	// Pages are parsed concurrently, the rows can arrive in either order
+	for _, rows := range [][]Entry{{pending, resolved}, {resolved, pending}} {
+		doc := &Document{Year: 2023, Entries: make(map[dossier.Number]Entry)}
+		for _, entry := range rows {
+			doc.add(entry)
+		}
+		if got := doc.State(number); got != StateFoundAndResolved {
+			t.Errorf("state after adding %v = %v, want resolved", rows, got)
+		}
+	}
+}
+
+func TestEntryState(t *testing.T) {
+	tests := []struct {
+		solution string
+		want     FindState
+	}{
+		{"", StateFoundButNotResolved},
+		{"în procesare", StateFoundButNotResolved},
+		{"123/P/2024 din 01.02.2024", StateFoundAndResolved},
+		{"Respins 12/P/2024", StateRejected},
+		{"respingere", StateRejected},
+		{"restituire dosar", StateReturned},
+		{"SUSPENDAT", StateSuspended},
+	}
+	for _, tt := range tests {
+		if got := (Entry{Solution: tt.solution}).State(); got != tt.want {
+			t.Errorf("State of %q = %v, want %v", tt.solution, got, tt.want)
+		}
+	}
+}
+
+// countingFetcher serves a placeholder file per year and counts downloads
+type countingFetcher struct {
+	fetcher.FileFetcher
+	calls map[int]int
+}
+
+func (f *countingFetcher) GetFile(year int) ([]byte, error) {
+	f.calls[year]++
+	if year == 2020 {
+		return nil, fmt.Errorf("not published")
+	}
+	return []byte(fmt.Sprintf("pdf %d", year)), nil
+}
+
+// textParser parses the fixture of a year instead of a PDF
+type textParser struct {
+	pages map[int]string
+}
+
+func (p textParser) ParseDocument(data []byte, year int) (*Document, error) {
+	doc := &Document{Year: year, Entries: make(map[dossier.Number]Entry)}
+	for _, entry := range parseEntries(p.pages[year]) {
+		doc.add(entry)
+	}
+	return doc, nil
+}
+
+func TestHandleManyGroupsByYear(t *testing.T) {
+	f := &countingFetcher{calls: make(map[int]int)}
+	s := &service{
+		fetcher: f,
+		parser: textParser{pages: map[int]string{
+			2023: pageFixture,
+			2024: "10/RD/2024 5/P/2025 din 02.01.2025",
+		}},
+		documents: make(map[int]cachedDocument),
+		parsing:   make(map[int]*pendingDocument),
+	}
+
+	searches := []string{"1/RD/2023", "10/RD/2024", "2 / rd / 2023", "nimic", "99/RD/2023", "1/RD/2020", "3/RD/2023"}
+	results, _, err := s.HandleMany(searches)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		number string
+		state  FindState
+		err    bool
+	}{
+		{"1/RD/2023", StateFoundAndResolved, false},
+		{"10/RD/2024", StateFoundAndResolved, false},
+		{"2/RD/2023", StateFoundButNotResolved, false},
+		{"nimic", StateNotFound, true},
+		{"99/RD/2023", StateNotFound, false},
+		{"1/RD/2020", StateNotFound, true},
+		{"3/RD/2023", StateRejected, false},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		result := results[i]
+		if result.DecreeNumber != w.number || result.State != w.state || (result.Err != nil) != w.err {
+			t.Errorf("result %d = {%s %v %v}, want {%s %v err=%v}", i, result.DecreeNumber, result.State, result.Err, w.number, w.state, w.err)
+		}
+	}
+
+	for year, calls := range f.calls {
+		if calls != 1 {
+			t.Errorf("the %d file was fetched %d times, want once", year, calls)
+		}
+	}
+	if len(f.calls) != 3 {
+		t.Errorf("fetched %d years, want 3: %v", len(f.calls), f.calls)
+	}
+}
+
+func TestDocumentCachedPerRevision(t *testing.T) {
+	parses := 0
+	s := &service{
+		fetcher:   &countingFetcher{calls: make(map[int]int)},
+		parser:    countingParser{parses: &parses},
+		documents: make(map[int]cachedDocument),
+		parsing:   make(map[int]*pendingDocument),
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.Document(2023); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if parses != 1 {
+		t.Errorf("parsed %d times, want the unchanged file parsed once", parses)
+	}
+}
+
+type countingParser struct {
+	parses *int
+}
+
+func (p countingParser) ParseDocument(data []byte, year int) (*Document, error) {
+	*p.parses++
+	return &Document{Year: year, Entries: make(map[dossier.Number]Entry)}, nil
+}
+
+// blockingParser holds every parse until release is closed
+type blockingParser struct {
+	parses  *atomic.Int32
+	release chan struct{}
+}
+
+func (p blockingParser) ParseDocument(data []byte, year int) (*Document, error) {
+	p.parses.Add(1)
+	<-p.release
+	return &Document{Year: year, Entries: make(map[dossier.Number]Entry)}, nil
+}
+
+func TestDocumentParsedOnceForConcurrentLookups(t *testing.T) {
+	var parses atomic.Int32
+	release := make(chan struct{})
+	s := &service{
+		fetcher:   &staticFetcher{},
+		parser:    blockingParser{parses: &parses, release: release},
+		documents: make(map[int]cachedDocument),
+		parsing:   make(map[int]*pendingDocument),
+	}
+
+	const lookups = 8
+	docs := make(chan *Document, lookups)
+	for i := 0; i < lookups; i++ {
+		go func() {
+			doc, err := s.Document(2023)
+			if err != nil {
+				t.Error(err)
+			}
+			docs <- doc
+		}()
+	}
+
+	// Let every lookup reach the parse before it completes
+	for {
+		s.mu.Lock()
+		_, parsing := s.parsing[2023]
+		s.mu.Unlock()
+		if parsing {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	first := <-docs
+	for i := 1; i < lookups; i++ {
+		if doc := <-docs; doc != first {
+			t.Error("lookups returned different documents")
+		}
+	}
+	if n := parses.Load(); n != 1 {
+		t.Errorf("parsed %d times, want once", n)
+	}
+}
+
+// staticFetcher serves the same placeholder file every time
+type staticFetcher struct {
+	fetcher.FileFetcher
+}
+
+func (f *staticFetcher) GetFile(year int) ([]byte, error) {
+	return []byte(fmt.Sprintf("pdf %d", year)), nil
+}
@@ -6,6 +6,16 @@ const (
 	StateNotFound FindState = iota
 	StateFoundButNotResolved
 	StateFoundAndResolved
+	// StateAwaitingOath and StateOathScheduled follow a resolution: they are
+	// never found in the annual PDFs, subscriptions reach them from the oath
+	// scheduling lists
+	StateAwaitingOath
+	StateOathScheduled
+	// StateRejected, StateReturned and StateSuspended are read from the
+	// solution column of the annual PDFs like StateFoundAndResolved
+	StateRejected
+	StateReturned
+	StateSuspended
 )
 
 func (s FindState) String() string {
@@ -16,7 +26,27 @@ func (s FindState) String() string {
 		return "Found but not resolved"
 	case StateFoundAndResolved:
 		return "Found and resolved"
+	case StateAwaitingOath:
+		return "Resolved, awaiting oath scheduling"
+	case StateOathScheduled:
+		return "Oath scheduled"
+	case StateRejected:
+		return "Rejected"
+	case StateReturned:
+		return "Returned"
+	case StateSuspended:
+		return "Suspended"
 	default:
 		return "Unknown state"
 	}
 }
+
+// Pending reports whether a listed dossier still waits for its outcome
+func (s FindState) Pending() bool {
+	return s == StateFoundButNotResolved || s == StateSuspended
+}
+
+// Closed reports whether the annual PDF lists the final outcome of the dossier
+func (s FindState) Closed() bool {
+	return s == StateFoundAndResolved || s == StateRejected || s == StateReturned
+}
@@ -0,0 +1,117 @@
+package decree
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// orderInputPattern matches an order number typed by a user, tolerating
+// spaces, backslashes and a lowercase "p"
+var orderInputPattern = regexp.MustCompile(`^(\d+)\s*[/\\]\s*[Pp]\s*[/\\]\s*(\d{4})$`)
+
+// Order is a resolution order with the dossiers it resolved
+type Order struct {
+	Number string
+	// Date is the date printed next to the order, zero if missing
+	Date     time.Time
+	Dossiers []Entry
+}
+
+// ParseOrderNumber returns the canonical form of an order number such as 1234/P/2024
+func ParseOrderNumber(input string) (string, error) {
+	groups := orderInputPattern.FindStringSubmatch(strings.TrimSpace(input))
+	if groups == nil {
+		return "", fmt.Errorf("format invalid, folosește [număr]/P/[an]")
+	}
+	value, err := strconv.Atoi(groups[1])
+	if err != nil || value == 0 {
+		return "", fmt.Errorf("număr de ordin invalid: %s", groups[1])
+	}
+	return fmt.Sprintf("%d/P/%s", value, groups[2]), nil
+}
+
+// CanonicalOrder drops the leading zeros some rows print in order numbers
+func CanonicalOrder(order string) string {
+	if canonical, err := ParseOrderNumber(order); err == nil {
+		return canonical
+	}
+	return order
+}
+
+// orderIndex maps the orders of a document to the rows they resolved. It is
+// built the first time an order is looked up.
+type orderIndex struct {
+	once   sync.Once
+	orders map[string]*Order
+}
+
+// Order returns the dossiers of the document resolved by an order
+func (d *Document) Order(number string) (Order, bool) {
+	d.orders.once.Do(func() {
+		d.orders.orders = make(map[string]*Order)
+		for _, entry := range d.Entries {
+			if entry.Order == "" {
+				continue
+			}
+			key := CanonicalOrder(entry.Order)
+			order, ok := d.orders.orders[key]
+			if !ok {
+				order = &Order{Number: key}
+				d.orders.orders[key] = order
+			}
+			if order.Date.IsZero() {
+				order.Date = entry.OrderDate
+			}
+			order.Dossiers = append(order.Dossiers, entry)
+		}
+		for _, order := range d.orders.orders {
+			sortEntries(order.Dossiers)
+		}
+	})
+
+	order, ok := d.orders.orders[CanonicalOrder(number)]
+	if !ok {
+		return Order{}, false
+	}
+	return *order, true
+}
+
+// FindOrder collects the dossiers an order resolved from the documents of
+// the given years, since one order resolves dossiers registered in several
+// years. Years whose document is not available before ctx is done are
+// skipped; an error is returned only if none was.
+func (s *service) FindOrder(ctx context.Context, number string, years []int) (Order, bool, error) {
+	number = CanonicalOrder(number)
+	docs, errs := s.loadDocuments(ctx, years)
+	if len(docs) == 0 && len(errs) > 0 {
+		return Order{}, false, errs[0]
+	}
+
+	merged := Order{Number: number}
+	found := false
+	for _, doc := range docs {
+		order, ok := doc.Order(number)
+		if !ok {
+			continue
+		}
+		found = true
+		if merged.Date.IsZero() {
+			merged.Date = order.Date
+		}
+		merged.Dossiers = append(merged.Dossiers, order.Dossiers...)
+	}
+	sort.Slice(merged.Dossiers, func(i, j int) bool {
+		a, b := merged.Dossiers[i].Number, merged.Dossiers[j].Number
+		if a.Year != b.Year {
+			return a.Year < b.Year
+		}
+		return a.Value < b.Value
+	})
+	return merged, found, nil
+}
@@ -1,8 +1,13 @@
 package decree
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sync"
 
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
 	"github.com/andiq123/cetatenie-analyzer/internal/fetcher"
 	"github.com/andiq123/cetatenie-analyzer/internal/timer"
 )
@@ -10,53 +15,219 @@ import (
 // Processor defines the interface for processing decree searches
 type Processor interface {
 	Handle(search string) (FindState, *timer.TimeReport, error)
+	HandleMany(searches []string) ([]Result, *timer.TimeReport, error)
+	Search(ctx context.Context, value int, years []int) []Result
+	FindOrder(ctx context.Context, number string, years []int) (Order, bool, error)
+	Report(searches []string) (*Report, error)
+	Document(year int) (*Document, error)
+	ParseRevision(year int, data []byte) (*Document, error)
+	Sources() []fetcher.Source
+	OathSources() []fetcher.Source
 	CleanUpCache() error
 }
 
+// Result is the outcome of a single dossier lookup within a batch
+type Result struct {
+	DecreeNumber string
+	State        FindState
+	Err          error
+}
+
+type cachedDocument struct {
+	hash [sha256.Size]byte
+	doc  *Document
+}
+
+// pendingDocument is a document being parsed. Lookups of the same revision
+// wait for done instead of parsing it again.
+type pendingDocument struct {
+	hash [sha256.Size]byte
+	done chan struct{}
+	doc  *Document
+	err  error
+}
+
 type service struct {
 	fetcher fetcher.FileFetcher
 	parser  IParser
-}
 
-func NewProcessor() Processor {
-	f, err := fetcher.New()
-	if err != nil {
-		panic(fmt.Errorf("failed to create fetcher: %v", err))
-	}
+	mu        sync.Mutex
+	documents map[int]cachedDocument
+	parsing   map[int]*pendingDocument
+}
 
+// NewProcessor creates a processor reading the annual PDFs downloaded by f
+func NewProcessor(f fetcher.FileFetcher) Processor {
 	return &service{
-		fetcher: f,
-		parser:  newParser(),
+		fetcher:   f,
+		parser:    newParser(),
+		documents: make(map[int]cachedDocument),
+		parsing:   make(map[int]*pendingDocument),
 	}
 }
 
 func (s *service) Handle(search string) (FindState, *timer.TimeReport, error) {
-	year, err := s.parser.GetYear(search)
+	match, err := dossier.Parse(search)
 	if err != nil {
 		return StateNotFound, &timer.TimeReport{}, fmt.Errorf("format dosar invalid: %v", err)
 	}
 
+	doc, timeReport, err := s.document(match.Number.Year)
+	if err != nil {
+		return StateNotFound, &timer.TimeReport{}, err
+	}
+
+	return doc.State(match.Number), timeReport, nil
+}
+
+// HandleMany looks up several dossiers at once, fetching and scanning each
+// year's document a single time. Per-dossier failures are reported in the
+// corresponding Result; results keep the order of searches.
+func (s *service) HandleMany(searches []string) ([]Result, *timer.TimeReport, error) {
+	lookups, total := s.lookupMany(searches)
+
+	results := make([]Result, len(lookups))
+	for i, lookup := range lookups {
+		results[i] = lookup.Result
+	}
+	return results, total, nil
+}
+
+// lookup is the outcome of a dossier lookup with the matching document row
+type lookup struct {
+	Result
+	number dossier.Number
+	entry  Entry
+	found  bool
+}
+
+// lookupMany resolves searches grouped by year, so each year's document is
+// fetched and scanned once
+func (s *service) lookupMany(searches []string) ([]lookup, *timer.TimeReport) {
+	lookups := make([]lookup, len(searches))
+	byYear := make(map[int][]int)
+
+	for i, search := range searches {
+		lookups[i].DecreeNumber = search
+		match, err := dossier.Parse(search)
+		if err != nil {
+			lookups[i].Err = fmt.Errorf("format dosar invalid: %v", err)
+			continue
+		}
+		lookups[i].DecreeNumber = match.Canonical()
+		lookups[i].number = match.Number
+		byYear[match.Number.Year] = append(byYear[match.Number.Year], i)
+	}
+
+	total := &timer.TimeReport{}
+	for year, indexes := range byYear {
+		doc, timeReport, err := s.document(year)
+		if err != nil {
+			for _, i := range indexes {
+				lookups[i].Err = err
+			}
+			continue
+		}
+		total.FetchTime += timeReport.FetchTime
+		total.ParseTime += timeReport.ParseTime
+
+		for _, i := range indexes {
+			lookups[i].entry, lookups[i].found = doc.Lookup(lookups[i].number)
+			lookups[i].State = doc.State(lookups[i].number)
+		}
+	}
+
+	return lookups, total
+}
+
+// document returns the parsed annual document for year. The PDF is parsed
+// again only when its content differs from the previously parsed revision.
+func (s *service) document(year int) (*Document, *timer.TimeReport, error) {
 	fetchTimer := timer.NewTimer()
 	fetchTimer.Start()
 	dataBytes, err := s.fetcher.GetFile(year)
 	if err != nil {
-		return StateNotFound, &timer.TimeReport{}, fmt.Errorf("nu am putut obține fișierul pentru anul %d: %v", year, err)
+		return nil, nil, fmt.Errorf("nu am putut obține fișierul pentru anul %d: %v", year, err)
 	}
 	fetchTimer.Stop()
 	fetchTime := fetchTimer.Duration()
 
 	parseTimer := timer.NewTimer()
 	parseTimer.Start()
-	state, err := s.parser.ReadPdf(dataBytes, search)
+	doc, err := s.parsed(year, dataBytes)
 	if err != nil {
-		return StateNotFound, &timer.TimeReport{}, fmt.Errorf("eroare la analiza documentului: %v", err)
+		return nil, nil, fmt.Errorf("eroare la analiza documentului: %v", err)
 	}
 	parseTimer.Stop()
 	parseTime := parseTimer.Duration()
 
-	timeReport := timer.NewTimeReport(fetchTime, parseTime)
+	return doc, timer.NewTimeReport(fetchTime, parseTime), nil
+}
+
+// parsed returns the document of year parsed from data, reusing the cached
+// one while the content is unchanged. Concurrent lookups of a revision not
+// parsed yet share a single parse.
+func (s *service) parsed(year int, data []byte) (*Document, error) {
+	hash := sha256.Sum256(data)
+
+	s.mu.Lock()
+	if cached, ok := s.documents[year]; ok && cached.hash == hash {
+		s.mu.Unlock()
+		return cached.doc, nil
+	}
+	if pending, ok := s.parsing[year]; ok && pending.hash == hash {
+		s.mu.Unlock()
+		<-pending.done
+		return pending.doc, pending.err
+	}
+	pending := &pendingDocument{hash: hash, done: make(chan struct{})}
+	s.parsing[year] = pending
+	s.mu.Unlock()
+
+	pending.doc, pending.err = s.parser.ParseDocument(data, year)
+	if pending.err == nil {
+		pending.doc.Revision = hex.EncodeToString(hash[:])
+	}
+
+	s.mu.Lock()
+	if pending.err == nil {
+		s.documents[year] = cachedDocument{hash: hash, doc: pending.doc}
+	}
+	if s.parsing[year] == pending {
+		delete(s.parsing, year)
+	}
+	s.mu.Unlock()
+	close(pending.done)
+
+	return pending.doc, pending.err
+}
+
+// Document returns the parsed annual document for year
+func (s *service) Document(year int) (*Document, error) {
+	doc, _, err := s.document(year)
+	return doc, err
+}
+
+// ParseRevision parses a past revision of an annual PDF, e.g. one kept in
+// the archive. The result is not cached.
+func (s *service) ParseRevision(year int, data []byte) (*Document, error) {
+	doc, err := s.parser.ParseDocument(data, year)
+	if err != nil {
+		return nil, fmt.Errorf("eroare la analiza documentului: %v", err)
+	}
+	hash := sha256.Sum256(data)
+	doc.Revision = hex.EncodeToString(hash[:])
+	return doc, nil
+}
+
+// Sources lists the annual documents and their last downloaded revision
+func (s *service) Sources() []fetcher.Source {
+	return s.fetcher.Sources()
+}
 
-	return state, timeReport, nil
+// OathSources lists the oath scheduling documents and their last downloaded revision
+func (s *service) OathSources() []fetcher.Source {
+	return s.fetcher.OathSources()
 }
 
 func (s *service) CleanUpCache() error {
@@ -0,0 +1,190 @@
+package decree
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/bulk"
+)
+
+// Report is the status of a list of dossiers at a point in time, meant to be
+// shared with the people who filed them
+type Report struct {
+	GeneratedAt time.Time
+	Rows        []ReportRow
+	// Labels optionally names the dossiers (e.g. after the client), keyed by
+	// canonical dossier number
+	Labels map[string]string
+}
+
+// ReportRow is the status of a single dossier within a Report
+type ReportRow struct {
+	DecreeNumber string
+	State        FindState
+	Order        string
+	OrderDate    time.Time
+	// Months is the time elapsed since the start of the registration year,
+	// up to the order date for resolved dossiers. The PDFs only list the
+	// registration year, so this is an upper bound.
+	Months int
+	Err    error
+}
+
+// Report looks up the given dossiers, scanning each year's document once,
+// and returns their status with order details
+func (s *service) Report(searches []string) (*Report, error) {
+	lookups, _ := s.lookupMany(searches)
+
+	now := time.Now()
+	report := &Report{GeneratedAt: now, Rows: make([]ReportRow, len(lookups))}
+	for i, lookup := range lookups {
+		row := ReportRow{
+			DecreeNumber: lookup.DecreeNumber,
+			State:        lookup.State,
+			Err:          lookup.Err,
+		}
+		if lookup.found {
+			row.Order = lookup.entry.Order
+			row.OrderDate = lookup.entry.OrderDate
+		}
+		if lookup.Err == nil {
+			end := now
+			if row.State == StateFoundAndResolved && !row.OrderDate.IsZero() {
+				end = row.OrderDate
+			}
+			row.Months = max(0, (end.Year()-lookup.number.Year)*12+int(end.Month())-1)
+		}
+		report.Rows[i] = row
+	}
+
+	return report, nil
+}
+
+// CSV renders the report as a CSV document
+func (r *Report) CSV() ([]byte, error) {
+	records := make([][]string, 0, len(r.Rows))
+	for _, row := range r.Rows {
+		record := []string{row.DecreeNumber, r.Labels[row.DecreeNumber], row.StateText(), row.Order, row.OrderDateText(), ""}
+		if row.Err == nil {
+			record[5] = fmt.Sprint(row.Months)
+		}
+		records = append(records, record)
+	}
+	return bulk.WriteRecords([]string{"dosar", "eticheta", "stare", "ordin", "data_ordin", "vechime_luni"}, records)
+}
+
+// HTML renders the report as a standalone HTML page that can be printed to PDF
+func (r *Report) HTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := reportTemplate.Execute(&buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Summary counts the report's dossiers per outcome. Suspended dossiers are
+// pending, rejected and returned ones are closed.
+func (r *Report) Summary() (resolved, pending, closed, notFound, failed int) {
+	for _, row := range r.Rows {
+		switch {
+		case row.Err != nil:
+			failed++
+		case row.State == StateFoundAndResolved:
+			resolved++
+		case row.State.Pending():
+			pending++
+		case row.State.Closed():
+			closed++
+		default:
+			notFound++
+		}
+	}
+	return
+}
+
+// StateText describes the row's state in Romanian
+func (r ReportRow) StateText() string {
+	if r.Err != nil {
+		return "eroare la verificare"
+	}
+	switch r.State {
+	case StateFoundAndResolved:
+		return "rezolvat"
+	case StateFoundButNotResolved:
+		return "în procesare"
+	case StateRejected:
+		return "respins"
+	case StateReturned:
+		return "restituit"
+	case StateSuspended:
+		return "suspendat"
+	default:
+		return "negăsit"
+	}
+}
+
+// OrderDateText formats the order date, empty when unknown
+func (r ReportRow) OrderDateText() string {
+	if r.OrderDate.IsZero() {
+		return ""
+	}
+	return r.OrderDate.Format("02.01.2006")
+}
+
+// AgeText formats the time since registration as years and months
+func (r ReportRow) AgeText() string {
+	if r.Err != nil {
+		return ""
+	}
+	years, months := r.Months/12, r.Months%12
+	switch {
+	case years == 0:
+		return fmt.Sprintf("%d luni", months)
+	case months == 0:
+		return fmt.Sprintf("%d ani", years)
+	default:
+		return fmt.Sprintf("%d ani, %d luni", years, months)
+	}
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="ro">
+<head>
+<meta charset="utf-8">
+<title>Raport dosare cetățenie</title>
+<style>
+body { font-family: Arial, sans-serif; margin: 2em; color: #222; }
+h1 { font-size: 1.4em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #ccc; padding: 6px 10px; text-align: left; }
+th { background: #f0f0f0; }
+.state-2 { color: #1a7f37; font-weight: bold; }
+.state-1 { color: #9a6700; }
+.state-0, .state-5, .state-6, .error { color: #cf222e; }
+.state-7 { color: #6e7781; }
+.note { font-size: 0.85em; color: #666; margin-top: 1em; }
+</style>
+</head>
+<body>
+<h1>Raport dosare cetățenie</h1>
+<p>Generat la {{.GeneratedAt.Format "02.01.2006 15:04"}} — {{len .Rows}} dosare</p>
+<table>
+<tr><th>Dosar</th><th>Etichetă</th><th>Stare</th><th>Ordin</th><th>Data ordinului</th><th>Vechime</th></tr>
+{{- $labels := .Labels}}
+{{- range .Rows}}
+<tr>
+<td>{{.DecreeNumber}}</td>
+<td>{{index $labels .DecreeNumber}}</td>
+<td class="{{if .Err}}error{{else}}state-{{printf "%d" .State}}{{end}}">{{.StateText}}</td>
+<td>{{.Order}}</td>
+<td>{{.OrderDateText}}</td>
+<td>{{.AgeText}}</td>
+</tr>
+{{- end}}
+</table>
+<p class="note">Vechimea este calculată de la începutul anului înregistrării, singura dată publicată în listele oficiale.</p>
+</body>
+</html>
+`))
@@ -0,0 +1,66 @@
+package decree
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+)
+
+// SearchBudget bounds how long a search across years waits for the documents
+const SearchBudget = 20 * time.Second
+
+// Search looks up the same dossier number in the documents of several
+// years concurrently. Years whose document is not available before ctx is
+// done are reported with an error. Results keep the order of years.
+func (s *service) Search(ctx context.Context, value int, years []int) []Result {
+	docs, _ := s.loadDocuments(ctx, years)
+
+	results := make([]Result, len(years))
+	for i, year := range years {
+		number := dossier.Number{Value: value, Year: year}
+		results[i] = Result{DecreeNumber: number.String()}
+		doc, ok := docs[year]
+		if !ok {
+			results[i].Err = fmt.Errorf("documentul din %d nu este disponibil", year)
+			continue
+		}
+		results[i].State = doc.State(number)
+	}
+	return results
+}
+
+// loadDocuments loads the documents of several years concurrently and returns
+// those available before ctx is done, with the errors of the failed ones
+func (s *service) loadDocuments(ctx context.Context, years []int) (map[int]*Document, []error) {
+	type loaded struct {
+		year int
+		doc  *Document
+		err  error
+	}
+	// Buffered so the downloads still running when ctx is done do not block
+	done := make(chan loaded, len(years))
+	for _, year := range years {
+		go func() {
+			doc, _, err := s.document(year)
+			done <- loaded{year: year, doc: doc, err: err}
+		}()
+	}
+
+	docs := make(map[int]*Document, len(years))
+	var errs []error
+	for range years {
+		select {
+		case <-ctx.Done():
+			return docs, append(errs, ctx.Err())
+		case result := <-done:
+			if result.err != nil {
+				errs = append(errs, result.err)
+				continue
+			}
+			docs[result.year] = result.doc
+		}
+	}
+	return docs, errs
+}
@@ -0,0 +1,54 @@
+package decree
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+)
+
+// Snapshot encodes the document's rows compactly so a later revision can be
+// compared against it after a restart
+func (d *Document) Snapshot() ([]byte, error) {
+	entries := make([]Entry, 0, len(d.Entries))
+	for _, entry := range d.Entries {
+		entries = append(entries, entry)
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(writer).Encode(entries); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DocumentFromSnapshot decodes a document encoded by Snapshot
+func DocumentFromSnapshot(year int, revision string, data []byte) (*Document, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("snapshot invalid: %w", err)
+	}
+	defer reader.Close()
+
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot invalid: %w", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, fmt.Errorf("snapshot invalid: %w", err)
+	}
+
+	doc := &Document{Year: year, Revision: revision, Entries: make(map[dossier.Number]Entry, len(entries))}
+	for _, entry := range entries {
+		doc.Entries[entry.Number] = entry
+	}
+	return doc, nil
+}
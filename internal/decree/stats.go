@@ -0,0 +1,195 @@
+package decree
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+)
+
+const (
+	// ThroughputWeeks is the number of recent weeks the resolution pace is measured over
+	ThroughputWeeks = 12
+	week            = 7 * 24 * time.Hour
+)
+
+// ErrNoThroughput is returned when no dossier of the year was resolved
+// recently enough to project a resolution date
+var ErrNoThroughput = errors.New("niciun dosar din acest an nu a fost rezolvat în ultimele săptămâni")
+
+// WeekThroughput is the number of dossiers of a year resolved in a week
+type WeekThroughput struct {
+	Start    time.Time
+	Resolved int
+}
+
+// Statistics summarizes how fast the dossiers of a year are resolved
+type Statistics struct {
+	Year int
+	// Weeks holds the last ThroughputWeeks complete weeks, oldest first
+	Weeks []WeekThroughput
+	// WeeklyRate is the median of the weekly throughput, LowRate and
+	// HighRate its lower and upper quartiles
+	WeeklyRate float64
+	LowRate    float64
+	HighRate   float64
+	// MedianWait is the median time from registration to order, zero when unknown
+	MedianWait time.Duration
+	Resolved   int
+	Pending    int
+}
+
+// Estimate is the projected resolution window of a pending dossier
+type Estimate struct {
+	PendingBefore int
+	Earliest      time.Time
+	Expected      time.Time
+	Latest        time.Time
+	// ByMedianWait is the registration date plus the median wait, zero when unknown
+	ByMedianWait time.Time
+}
+
+// PendingBefore counts the dossiers registered before number that are still pending
+func (d *Document) PendingBefore(number dossier.Number) int {
+	pending := 0
+	for other, entry := range d.Entries {
+		if other.Value < number.Value && entry.State().Pending() {
+			pending++
+		}
+	}
+	return pending
+}
+
+// Statistics measures the resolution pace of the document's year. A dossier
+// counts as resolved on the date printed next to its order or, when the PDF
+// has none, on the date firstSeen reports for it: the day an archived
+// revision first listed its order. The registration date is not published,
+// it is approximated from the dossier number as if the dossiers were
+// registered at a steady pace over the year.
+func (d *Document) Statistics(firstSeen map[dossier.Number]time.Time, now time.Time) Statistics {
+	stats := Statistics{Year: d.Year}
+
+	// The current week is still in progress, only complete weeks are measured
+	windowEnd := startOfWeek(now)
+	windowStart := windowEnd.Add(-ThroughputWeeks * week)
+	weekly := make([]int, ThroughputWeeks)
+	var waits []time.Duration
+
+	for number, entry := range d.Entries {
+		switch state := entry.State(); {
+		case state.Pending():
+			stats.Pending++
+			continue
+		case state != StateFoundAndResolved:
+			continue
+		}
+		stats.Resolved++
+
+		resolvedAt := entry.OrderDate
+		if resolvedAt.IsZero() {
+			resolvedAt = firstSeen[number]
+		}
+		if resolvedAt.IsZero() {
+			continue
+		}
+
+		if !resolvedAt.Before(windowStart) && resolvedAt.Before(windowEnd) {
+			weekly[int(resolvedAt.Sub(windowStart)/week)]++
+		}
+		if wait := resolvedAt.Sub(d.registrationDate(number, now)); wait > 0 {
+			waits = append(waits, wait)
+		}
+	}
+
+	for i, resolved := range weekly {
+		stats.Weeks = append(stats.Weeks, WeekThroughput{Start: windowStart.Add(time.Duration(i) * week), Resolved: resolved})
+	}
+	rates := make([]float64, len(weekly))
+	for i, resolved := range weekly {
+		rates[i] = float64(resolved)
+	}
+	sort.Float64s(rates)
+	stats.LowRate = quantile(rates, 0.25)
+	stats.WeeklyRate = quantile(rates, 0.5)
+	stats.HighRate = quantile(rates, 0.75)
+
+	if len(waits) > 0 {
+		sort.Slice(waits, func(i, j int) bool { return waits[i] < waits[j] })
+		stats.MedianWait = waits[len(waits)/2]
+	}
+	return stats
+}
+
+// Estimate projects when a pending dossier will be resolved, assuming the
+// dossiers registered before it are resolved first at the recent weekly
+// pace. The window spans the upper to the lower quartile of that pace.
+func (s Statistics) Estimate(doc *Document, number dossier.Number, now time.Time) (Estimate, error) {
+	// Sparse weeks can make the quartiles zero, fall back to the slowest week with orders
+	lowRate := s.LowRate
+	if lowRate == 0 {
+		for _, throughput := range s.Weeks {
+			if throughput.Resolved > 0 && (lowRate == 0 || float64(throughput.Resolved) < lowRate) {
+				lowRate = float64(throughput.Resolved)
+			}
+		}
+	}
+	if lowRate == 0 {
+		return Estimate{}, ErrNoThroughput
+	}
+	rate := math.Max(s.WeeklyRate, lowRate)
+	highRate := math.Max(s.HighRate, rate)
+
+	estimate := Estimate{PendingBefore: doc.PendingBefore(number)}
+	queue := float64(estimate.PendingBefore + 1)
+	estimate.Earliest = now.Add(weeks(queue / highRate))
+	estimate.Expected = now.Add(weeks(queue / rate))
+	estimate.Latest = now.Add(weeks(queue / lowRate))
+
+	if s.MedianWait > 0 {
+		estimate.ByMedianWait = doc.registrationDate(number, now).Add(s.MedianWait)
+	}
+	return estimate, nil
+}
+
+// registrationDate approximates when a dossier was registered from its
+// position among the numbers of its year. The current year is spread up to now.
+func (d *Document) registrationDate(number dossier.Number, now time.Time) time.Time {
+	start := time.Date(d.Year, time.January, 1, 0, 0, 0, 0, now.Location())
+	end := start.AddDate(1, 0, 0)
+	if now.Before(end) {
+		end = now
+	}
+
+	highest := 0
+	for other := range d.Entries {
+		highest = max(highest, other.Value)
+	}
+	if highest == 0 || !end.After(start) {
+		return start
+	}
+	share := math.Min(float64(number.Value)/float64(highest), 1)
+	return start.Add(time.Duration(share * float64(end.Sub(start))))
+}
+
+// quantile returns the q-quantile of sorted values by linear interpolation
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	position := q * float64(len(sorted)-1)
+	lower := int(math.Floor(position))
+	upper := int(math.Ceil(position))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+}
+
+func startOfWeek(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := (int(day.Weekday()) + 6) % 7 // Monday is the first day of the week
+	return day.AddDate(0, 0, -offset)
+}
+
+func weeks(n float64) time.Duration {
+	return time.Duration(n * float64(week))
+}
@@ -0,0 +1,205 @@
+// Package dossier normalizes and extracts citizenship dossier identifiers
+// ([număr]/RD/[an]) from free-form user input
+package dossier
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MaxNumber is the highest dossier number accepted (five digits)
+	MaxNumber = 99999
+	// MinYear and MaxYear bound the registration year of a dossier
+	MinYear = 2000
+	MaxYear = 2100
+)
+
+// identifierPattern matches a dossier identifier with the common mistakes
+// users make: spaces around separators, backslashes or full-width slashes,
+// lowercase letters and Cyrillic look-alikes for "RD". Go's regexp has no
+// lookbehind, the boundary before the number is checked by gluedBefore.
+var identifierPattern = regexp.MustCompile(`(\d+)\s*([/\\／])\s*([RrРр])\s*([DdДд])\s*([/\\／])\s*(\d{4})`)
+
+// Number is a canonical dossier identifier
+type Number struct {
+	Value int
+	Year  int
+}
+
+// String returns the canonical [număr]/RD/[an] representation
+func (n Number) String() string {
+	return fmt.Sprintf("%d/RD/%d", n.Value, n.Year)
+}
+
+// Match is a dossier identifier found in a text together with the
+// corrections that were applied to canonicalize it
+type Match struct {
+	Number      Number
+	Raw         string
+	Corrections []string
+}
+
+// Canonical returns the canonical representation of the matched identifier
+func (m Match) Canonical() string {
+	return m.Number.String()
+}
+
+// Corrected reports whether the raw input differed from the canonical form
+func (m Match) Corrected() bool {
+	return len(m.Corrections) > 0
+}
+
+// Extract finds every dossier identifier in text, in order of appearance and
+// without duplicates. Identifiers that cannot be valid (number too long,
+// year out of range) are skipped.
+func Extract(text string) []Match {
+	indexes := identifierPattern.FindAllStringSubmatchIndex(text, -1)
+	matches := make([]Match, 0, len(indexes))
+	seen := make(map[Number]bool, len(indexes))
+
+	for _, idx := range indexes {
+		// Reject identifiers glued to surrounding text, e.g. "/RD/20234" or "A9123/RD/2023"
+		if idx[1] < len(text) && isDigit(text[idx[1]]) || gluedBefore(text, idx[0]) {
+			continue
+		}
+
+		match, err := newMatch(text, idx)
+		if err != nil || seen[match.Number] {
+			continue
+		}
+		seen[match.Number] = true
+		matches = append(matches, match)
+	}
+
+	return matches
+}
+
+// Parse extracts exactly one dossier identifier from input. Surrounding text
+// such as "nr." is tolerated, but several identifiers are rejected.
+func Parse(input string) (Match, error) {
+	indexes := identifierPattern.FindAllStringSubmatchIndex(input, -1)
+	switch len(indexes) {
+	case 0:
+		return Match{}, fmt.Errorf("format invalid, folosește [număr]/RD/[an]")
+	case 1:
+	default:
+		return Match{}, fmt.Errorf("mesajul conține mai multe numere de dosar, trimite unul singur")
+	}
+
+	idx := indexes[0]
+	if idx[1] < len(input) && isDigit(input[idx[1]]) {
+		return Match{}, fmt.Errorf("anul trebuie să aibă 4 cifre")
+	}
+	if gluedBefore(input, idx[0]) {
+		return Match{}, fmt.Errorf("numărul dosarului trebuie separat de textul dinaintea lui")
+	}
+
+	match, err := newMatch(input, idx)
+	if err != nil {
+		return Match{}, err
+	}
+	if strings.TrimSpace(input) != match.Raw {
+		match.Corrections = append(match.Corrections, "am extras numărul dosarului din mesaj")
+	}
+	return match, nil
+}
+
+// ParseLeading parses a dossier identifier at the start of input and returns
+// the text following it, e.g. "123/RD/2023 Mama" yields 123/RD/2023 and "Mama"
+func ParseLeading(input string) (Match, string, error) {
+	input = strings.TrimSpace(input)
+	idx := identifierPattern.FindStringSubmatchIndex(input)
+	if idx == nil || idx[0] != 0 {
+		return Match{}, "", fmt.Errorf("format invalid, folosește [număr]/RD/[an]")
+	}
+	if idx[1] < len(input) && isDigit(input[idx[1]]) {
+		return Match{}, "", fmt.Errorf("anul trebuie să aibă 4 cifre")
+	}
+
+	match, err := newMatch(input, idx)
+	if err != nil {
+		return Match{}, "", err
+	}
+	return match, strings.TrimSpace(input[idx[1]:]), nil
+}
+
+// Normalize returns the canonical form of a single dossier identifier
+func Normalize(input string) (string, error) {
+	match, err := Parse(input)
+	if err != nil {
+		return "", err
+	}
+	return match.Canonical(), nil
+}
+
+// newMatch builds a Match from a submatch index of identifierPattern
+func newMatch(text string, idx []int) (Match, error) {
+	group := func(i int) string {
+		return text[idx[2*i]:idx[2*i+1]]
+	}
+
+	raw := text[idx[0]:idx[1]]
+	digits := group(1)
+	trimmed := strings.TrimLeft(digits, "0")
+	if trimmed == "" {
+		return Match{}, fmt.Errorf("numărul dosarului nu poate fi 0")
+	}
+	if len(trimmed) > len(strconv.Itoa(MaxNumber)) {
+		return Match{}, fmt.Errorf("numărul dosarului are prea multe cifre: %s", digits)
+	}
+
+	value, err := strconv.Atoi(trimmed)
+	if err != nil {
+		return Match{}, fmt.Errorf("număr invalid: %s", digits)
+	}
+	year, err := strconv.Atoi(group(6))
+	if err != nil {
+		return Match{}, fmt.Errorf("an invalid: %s", group(6))
+	}
+	if year < MinYear || year > MaxYear {
+		return Match{}, fmt.Errorf("anul %d este în afara intervalului valid", year)
+	}
+
+	match := Match{
+		Number: Number{Value: value, Year: year},
+		Raw:    raw,
+	}
+
+	if trimmed != digits {
+		match.Corrections = append(match.Corrections, "am eliminat zerourile din fața numărului")
+	}
+	if strings.ContainsAny(raw, " \t\n\r") {
+		match.Corrections = append(match.Corrections, "am eliminat spațiile")
+	}
+	if group(2) != "/" || group(5) != "/" {
+		match.Corrections = append(match.Corrections, "am înlocuit separatorii cu „/”")
+	}
+	letters := group(3) + group(4)
+	switch {
+	case strings.ContainsAny(letters, "РрДд"):
+		match.Corrections = append(match.Corrections, "am înlocuit literele chirilice cu „RD”")
+	case letters != "RD":
+		match.Corrections = append(match.Corrections, "am scris „RD” cu majuscule")
+	}
+
+	return match, nil
+}
+
+// gluedBefore reports whether the identifier starting at start continues a
+// longer token, i.e. it is preceded by a letter or digit
+func gluedBefore(text string, start int) bool {
+	if start == 0 {
+		return false
+	}
+	r, _ := utf8.DecodeLastRuneInString(text[:start])
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
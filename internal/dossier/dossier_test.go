@@ -0,0 +1,146 @@
+package dossier
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		want      string
+		corrected bool
+		wantErr   bool
+	}{
+		{name: "canonical", input: "123/RD/2023", want: "123/RD/2023"},
+		{name: "surrounding spaces", input: "  123/RD/2023  ", want: "123/RD/2023"},
+		{name: "spaces around separators", input: "123 / RD / 2023", want: "123/RD/2023", corrected: true},
+		{name: "lowercase", input: "123/rd/2023", want: "123/RD/2023", corrected: true},
+		{name: "cyrillic letters", input: "123/РД/2023", want: "123/RD/2023", corrected: true},
+		{name: "cyrillic lowercase", input: "123/рд/2023", want: "123/RD/2023", corrected: true},
+		{name: "full-width slashes", input: "123／RD／2023", want: "123/RD/2023", corrected: true},
+		{name: "backslashes", input: `123\RD\2023`, want: "123/RD/2023", corrected: true},
+		{name: "leading zeros", input: "00123/RD/2023", want: "123/RD/2023", corrected: true},
+		{name: "surrounding text", input: "dosarul nr. 123/RD/2023 vă rog", want: "123/RD/2023", corrected: true},
+		{name: "highest number", input: "99999/RD/2023", want: "99999/RD/2023"},
+		{name: "bounds of years", input: "1/RD/2000", want: "1/RD/2000"},
+
+		{name: "empty", input: "", wantErr: true},
+		{name: "no identifier", input: "salut", wantErr: true},
+		{name: "missing letters", input: "123/2023", wantErr: true},
+		{name: "number too long", input: "123456/RD/2023", wantErr: true},
+		{name: "number zero", input: "000/RD/2023", wantErr: true},
+		{name: "year too early", input: "123/RD/1999", wantErr: true},
+		{name: "year too late", input: "123/RD/2101", wantErr: true},
+		{name: "five digit year", input: "123/RD/20234", wantErr: true},
+		{name: "several identifiers", input: "1/RD/2023 2/RD/2023", wantErr: true},
+		{name: "glued to letters", input: "A9123/RD/2023", wantErr: true},
+		{name: "glued inside a token", input: "ref-x9123/RD/2023", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := Parse(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %s, want an error", tt.input, match.Canonical())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.input, err)
+			}
+			if got := match.Canonical(); got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+			if match.Corrected() != tt.corrected {
+				t.Errorf("Parse(%q) corrected = %v (%v), want %v", tt.input, match.Corrected(), match.Corrections, tt.corrected)
+			}
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "none", text: "bună ziua", want: nil},
+		{name: "single", text: "am dosarul 123/RD/2023", want: []string{"123/RD/2023"}},
+		{
+			name: "several in order",
+			text: "45/RD/2022, 123/rd/2023\n7 / РД / 2021",
+			want: []string{"45/RD/2022", "123/RD/2023", "7/RD/2021"},
+		},
+		{name: "duplicates once", text: "123/RD/2023 și 0123/rd/2023", want: []string{"123/RD/2023"}},
+		{name: "mixed separators", text: `1\RD\2023 2／RD／2023`, want: []string{"1/RD/2023", "2/RD/2023"}},
+		{name: "invalid ones skipped", text: "123456/RD/2023 5/RD/1990 6/RD/2023", want: []string{"6/RD/2023"}},
+		{name: "glued year skipped", text: "1/RD/20231 2/RD/2023", want: []string{"2/RD/2023"}},
+		{name: "glued number skipped", text: "ID9123/RD/2023 9124/RD/2023", want: []string{"9124/RD/2023"}},
+		{name: "punctuation is a boundary", text: "(123/RD/2023), #124/RD/2023", want: []string{"123/RD/2023", "124/RD/2023"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, match := range Extract(tt.text) {
+				got = append(got, match.Canonical())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "123/RD/2023", want: "123/RD/2023"},
+		{input: " 0042 \\ рд / 2024 ", want: "42/RD/2024"},
+		{input: "42／Rd／2024", want: "42/RD/2024"},
+		{input: "42/RD", wantErr: true},
+		{input: "100000/RD/2024", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Normalize(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Normalize(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseLeading(t *testing.T) {
+	tests := []struct {
+		input    string
+		want     string
+		wantRest string
+		wantErr  bool
+	}{
+		{input: "123/RD/2023 Mama", want: "123/RD/2023", wantRest: "Mama"},
+		{input: "123 / rd / 2023", want: "123/RD/2023"},
+		{input: "Mama 123/RD/2023", wantErr: true},
+		{input: "123/RD/20234 Mama", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		match, rest, err := ParseLeading(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLeading(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (match.Canonical() != tt.want || rest != tt.wantRest) {
+			t.Errorf("ParseLeading(%q) = %s, %q, want %s, %q", tt.input, match.Canonical(), rest, tt.want, tt.wantRest)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package dossier
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrNotRange is returned by ParseRange when the input is not a range at all,
+// so callers can fall back to parsing a single identifier
+var ErrNotRange = errors.New("nu este un interval de dosare")
+
+// rangePattern matches "1000-1500/RD/2022" or "*/RD/2022" at the start of the
+// input, with the same tolerance for separators and letters as identifierPattern
+var rangePattern = regexp.MustCompile(`^(?:(\d+)\s*[-–]\s*(\d+)|\*)\s*[/\\／]\s*[RrРр]\s*[DdДд]\s*[/\\／]\s*(\d{4})(?:\s|$)`)
+
+// Range is a span of dossier numbers registered in the same year. A range
+// covering every number stands for the whole year.
+type Range struct {
+	From int
+	To   int
+	Year int
+}
+
+// YearRange returns the range of every dossier registered in year
+func YearRange(year int) Range {
+	return Range{From: 1, To: MaxNumber, Year: year}
+}
+
+// Whole reports whether the range covers the whole year
+func (r Range) Whole() bool {
+	return r.From == 1 && r.To == MaxNumber
+}
+
+// Contains reports whether the dossier falls within the range
+func (r Range) Contains(n Number) bool {
+	return n.Year == r.Year && n.Value >= r.From && n.Value <= r.To
+}
+
+// String returns the canonical "1000-1500/RD/2022" or "*/RD/2022" form
+func (r Range) String() string {
+	if r.Whole() {
+		return fmt.Sprintf("*/RD/%d", r.Year)
+	}
+	return fmt.Sprintf("%d-%d/RD/%d", r.From, r.To, r.Year)
+}
+
+// ParseRange parses a range at the start of input and returns the text
+// following it. It returns ErrNotRange when input does not start with a range.
+func ParseRange(input string) (Range, string, error) {
+	input = strings.TrimSpace(input)
+	idx := rangePattern.FindStringSubmatchIndex(input)
+	if idx == nil {
+		return Range{}, "", ErrNotRange
+	}
+	rest := strings.TrimSpace(input[idx[1]:])
+
+	year, err := strconv.Atoi(input[idx[6]:idx[7]])
+	if err != nil || year < MinYear || year > MaxYear {
+		return Range{}, "", fmt.Errorf("anul %s este în afara intervalului valid", input[idx[6]:idx[7]])
+	}
+	if idx[2] < 0 {
+		return YearRange(year), rest, nil
+	}
+
+	from, fromErr := strconv.Atoi(input[idx[2]:idx[3]])
+	to, toErr := strconv.Atoi(input[idx[4]:idx[5]])
+	switch {
+	case fromErr != nil || toErr != nil || from < 1 || to > MaxNumber:
+		return Range{}, "", fmt.Errorf("numerele intervalului trebuie să fie între 1 și %d", MaxNumber)
+	case from > to:
+		return Range{}, "", fmt.Errorf("începutul intervalului (%d) este după sfârșitul lui (%d)", from, to)
+	}
+	return Range{From: from, To: to, Year: year}, rest, nil
+}
@@ -0,0 +1,199 @@
+// Package feed publishes the orders of every new PDF revision to a Telegram channel
+package feed
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/bulk"
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/andiq123/cetatenie-analyzer/internal/revision"
+)
+
+// maxRevisionAge keeps revisions recorded long before the feed was
+// configured, or while it was failing, from being announced as news
+const maxRevisionAge = 48 * time.Hour
+
+// Publisher sends the announcements to the channel
+type Publisher interface {
+	SendMessage(ctx context.Context, chatID int64, text string) error
+	SendDocument(ctx context.Context, chatID int64, filename string, data []byte, caption string) error
+}
+
+// Config is read from FEED_CHANNEL_ID, the ID of the channel to publish to
+// (the bot must be one of its admins), and FEED_ATTACH_LIST, which attaches
+// the full list of resolved dossiers as a CSV document
+type Config struct {
+	ChannelID  int64
+	AttachList bool
+}
+
+// LoadConfig reads the feed configuration; ok is false when no channel is configured
+func LoadConfig() (Config, bool) {
+	value := strings.TrimSpace(os.Getenv("FEED_CHANNEL_ID"))
+	if value == "" {
+		return Config{}, false
+	}
+	channelID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		fmt.Printf("Ignoring invalid FEED_CHANNEL_ID %q: %v\n", value, err)
+		return Config{}, false
+	}
+	attachList, _ := strconv.ParseBool(os.Getenv("FEED_ATTACH_LIST"))
+	return Config{ChannelID: channelID, AttachList: attachList}, true
+}
+
+// Service defines the interface for the channel feed
+type Service interface {
+	PublishNewRevisions(ctx context.Context) error
+}
+
+// service implements the Service interface
+type service struct {
+	config          Config
+	processor       decree.Processor
+	revisions       revision.Service
+	revisionService database.RevisionService
+	publisher       Publisher
+}
+
+// NewService creates a new instance of the channel feed
+func NewService(config Config, processor decree.Processor, revisions revision.Service, revisionService database.RevisionService, publisher Publisher) Service {
+	return &service{
+		config:          config,
+		processor:       processor,
+		revisions:       revisions,
+		revisionService: revisionService,
+		publisher:       publisher,
+	}
+}
+
+// PublishNewRevisions records the current revision of every annual PDF and
+// announces the revisions with newly resolved dossiers that were not
+// announced yet. Each revision is announced at most once.
+func (s *service) PublishNewRevisions(ctx context.Context) error {
+	for _, source := range s.processor.Sources() {
+		if _, err := s.revisions.Track(source.Year); err != nil {
+			fmt.Printf("Error tracking revision of %d: %v\n", source.Year, err)
+		}
+	}
+
+	pending, err := s.revisionService.GetUnannouncedRevisions()
+	if err != nil {
+		return fmt.Errorf("error getting unannounced revisions: %w", err)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(pending))
+	var announced []database.Revision
+	for _, revision := range pending {
+		ids = append(ids, revision.ID)
+		if !revision.Baseline() && revision.NewlyResolved > 0 && time.Since(revision.CreatedAt) < maxRevisionAge {
+			announced = append(announced, revision)
+		}
+	}
+
+	if len(announced) > 0 {
+		if err := s.announce(ctx, announced); err != nil {
+			return err
+		}
+		fmt.Printf("Announced %d revisions to channel %d\n", len(announced), s.config.ChannelID)
+	}
+
+	// The summary is out: the revisions are announced even if the list below
+	// fails, or the next run would post the same summary again
+	if err := s.revisionService.MarkAnnounced(ids); err != nil {
+		return fmt.Errorf("error marking revisions as announced: %w", err)
+	}
+
+	if len(announced) > 0 && s.config.AttachList {
+		if err := s.attachList(ctx, announced); err != nil {
+			fmt.Printf("Error attaching resolved list to channel %d: %v\n", s.config.ChannelID, err)
+		}
+	}
+	return nil
+}
+
+// yearSummary aggregates the announced revisions of one year
+type yearSummary struct {
+	year            int
+	resolved        int
+	highestResolved int
+}
+
+func (s *service) announce(ctx context.Context, revisions []database.Revision) error {
+	summaries := make(map[int]*yearSummary)
+	for _, revision := range revisions {
+		summary, ok := summaries[revision.Year]
+		if !ok {
+			summary = &yearSummary{year: revision.Year}
+			summaries[revision.Year] = summary
+		}
+		summary.resolved += revision.NewlyResolved
+		// Revisions are oldest first, the latest one decides the highest number
+		summary.highestResolved = revision.HighestResolved
+	}
+
+	years := make([]*yearSummary, 0, len(summaries))
+	total := 0
+	for _, summary := range summaries {
+		years = append(years, summary)
+		total += summary.resolved
+	}
+	sort.Slice(years, func(i, j int) bool { return years[i].year > years[j].year })
+
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("📰 <b>Ordine noi publicate</b> — %s\n\n", time.Now().Format("02.01.2006")))
+	for _, summary := range years {
+		message.WriteString(fmt.Sprintf("📅 <b>%d</b>: %d dosare rezolvate\n", summary.year, summary.resolved))
+		if summary.highestResolved > 0 {
+			highest := dossier.Number{Value: summary.highestResolved, Year: summary.year}
+			message.WriteString(fmt.Sprintf("   cel mai mare număr rezolvat: <code>%s</code>\n", highest))
+		}
+	}
+	message.WriteString(fmt.Sprintf("\n✅ Total: <b>%d dosare</b>", total))
+
+	if err := s.publisher.SendMessage(ctx, s.config.ChannelID, message.String()); err != nil {
+		return fmt.Errorf("error publishing to channel: %w", err)
+	}
+	return nil
+}
+
+// attachList sends the newly resolved dossiers of the revisions as a CSV document
+func (s *service) attachList(ctx context.Context, revisions []database.Revision) error {
+	data, err := s.resolvedList(revisions)
+	if err != nil {
+		return fmt.Errorf("error building resolved list: %w", err)
+	}
+	filename := fmt.Sprintf("ordine_%s.csv", time.Now().Format("2006-01-02"))
+	if err := s.publisher.SendDocument(ctx, s.config.ChannelID, filename, data, "📎 Lista completă a dosarelor rezolvate"); err != nil {
+		return fmt.Errorf("error publishing document: %w", err)
+	}
+	return nil
+}
+
+// resolvedList writes the newly resolved dossiers of the revisions as a CSV document
+func (s *service) resolvedList(revisions []database.Revision) ([]byte, error) {
+	var records [][]string
+	for _, revision := range revisions {
+		changes, err := s.revisionService.GetChanges(revision.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, change := range changes {
+			if change.Kind == database.ChangeResolved {
+				records = append(records, []string{strconv.Itoa(revision.Year), change.DecreeNumber, change.Order})
+			}
+		}
+	}
+	return bulk.WriteRecords([]string{"an", "dosar", "ordin"}, records)
+}
@@ -0,0 +1,86 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/fetcher"
+)
+
+type noSources struct {
+	decree.Processor
+}
+
+func (noSources) Sources() []fetcher.Source {
+	return nil
+}
+
+// revisionStore serves its revisions until they are marked announced
+type revisionStore struct {
+	database.RevisionService
+	revisions []database.Revision
+	announced map[uint]bool
+}
+
+func (f *revisionStore) GetUnannouncedRevisions() ([]database.Revision, error) {
+	var pending []database.Revision
+	for _, revision := range f.revisions {
+		if !f.announced[revision.ID] {
+			pending = append(pending, revision)
+		}
+	}
+	return pending, nil
+}
+
+func (f *revisionStore) MarkAnnounced(ids []uint) error {
+	for _, id := range ids {
+		f.announced[id] = true
+	}
+	return nil
+}
+
+func (f *revisionStore) GetChanges(revisionID uint) ([]database.RevisionChange, error) {
+	return []database.RevisionChange{{Kind: database.ChangeResolved, DecreeNumber: "1/RD/2023", Order: "12/P/2024"}}, nil
+}
+
+type channel struct {
+	messages    int
+	documents   int
+	documentErr error
+}
+
+func (c *channel) SendMessage(ctx context.Context, chatID int64, text string) error {
+	c.messages++
+	return nil
+}
+
+func (c *channel) SendDocument(ctx context.Context, chatID int64, filename string, data []byte, caption string) error {
+	c.documents++
+	return c.documentErr
+}
+
+func TestPublishNewRevisionsAnnouncesOnceWhenListFails(t *testing.T) {
+	store := &revisionStore{
+		revisions: []database.Revision{{ID: 2, Year: 2023, PreviousHash: "a", NewlyResolved: 3, CreatedAt: time.Now()}},
+		announced: make(map[uint]bool),
+	}
+	publisher := &channel{documentErr: errors.New("file too big")}
+	s := NewService(Config{ChannelID: -100, AttachList: true}, noSources{}, nil, store, publisher)
+
+	for run := 0; run < 2; run++ {
+		if err := s.PublishNewRevisions(context.Background()); err != nil {
+			t.Fatalf("run %d: %v", run, err)
+		}
+	}
+
+	if publisher.messages != 1 || publisher.documents != 1 {
+		t.Errorf("sent %d summaries and %d lists, want each once", publisher.messages, publisher.documents)
+	}
+	if !store.announced[2] {
+		t.Error("the revision was not marked announced")
+	}
+}
@@ -2,10 +2,14 @@ package fetcher
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -13,15 +17,51 @@ import (
 	"github.com/andiq123/cetatenie-analyzer/internal/cache"
 )
 
+// Archive keeps every downloaded revision of the annual PDFs
+type Archive interface {
+	Store(year int, data []byte, header http.Header, fetchedAt time.Time) error
+}
+
 type FileFetcher interface {
 	GetFile(year int) ([]byte, error)
+	GetOathSchedule(url string) ([]byte, error)
+	Sources() []Source
+	OathSources() []Source
 	CleanUpCache() error
 }
 
+// Kinds of documents the fetcher downloads
+const (
+	// KindOrders are the annual PDFs listing the dossiers of a year and their orders
+	KindOrders = "ordine"
+	// KindOath are the lists scheduling the oath ceremonies of resolved dossiers
+	KindOath = "juramant"
+)
+
+// Source describes a document and its last downloaded revision. Year is
+// set for the annual PDFs only.
+type Source struct {
+	Kind      string
+	Year      int
+	URL       string
+	Hash      string // hex SHA-256 of the last downloaded revision, empty if never downloaded
+	Size      int
+	FetchedAt time.Time
+	Cached    bool
+}
+
 type httpFetcher struct {
 	client  *http.Client
 	baseURL string
 	cache   *cache.Cache
+	archive Archive
+
+	// oathURLs lists the oath scheduling documents, PDF or HTML
+	oathURLs []string
+
+	mu            sync.RWMutex
+	revisions     map[int]Source
+	oathRevisions map[string]Source
 }
 
 var supportedYears = map[int]string{
@@ -33,8 +73,11 @@ var supportedYears = map[int]string{
 	2025: "art_11_anul_2025.pdf",
 }
 
-// New creates a new HTTP file fetcher instance with proper configuration
-func New() (FileFetcher, error) {
+// New creates a new HTTP file fetcher instance with proper configuration.
+// Every download of an annual PDF is handed to archive, unless it is nil.
+// The oath scheduling documents are read from the comma separated
+// OATH_SCHEDULE_URLS environment variable.
+func New(archive Archive) (FileFetcher, error) {
 	transport := &http.Transport{
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: true,
@@ -50,8 +93,12 @@ func New() (FileFetcher, error) {
 			Transport: transport,
 			Timeout:   60 * time.Second,
 		},
-		baseURL: "https://cetatenie.just.ro/storage/2023/11/",
-		cache:   cache.New(24 * time.Hour),
+		baseURL:       "https://cetatenie.just.ro/storage/2023/11/",
+		cache:         cache.New(24 * time.Hour),
+		archive:       archive,
+		oathURLs:      loadOathScheduleURLs(),
+		revisions:     make(map[int]Source),
+		oathRevisions: make(map[string]Source),
 	}, nil
 }
 
@@ -69,7 +116,44 @@ func (f *httpFetcher) GetFile(year int) ([]byte, error) {
 		return data, nil
 	}
 
-	data, err := f.downloadFileWithRetry(url, 3) // 3 retries
+	data, header, err := f.downloadFileWithRetry(url, 3, "application/pdf") // 3 retries
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	fetchedAt := time.Now()
+
+	f.cache.Set(url, data)
+	f.cache.Cleanup()
+
+	hash := sha256.Sum256(data)
+	f.mu.Lock()
+	f.revisions[year] = Source{
+		Kind:      KindOrders,
+		Year:      year,
+		URL:       url,
+		Hash:      hex.EncodeToString(hash[:]),
+		Size:      len(data),
+		FetchedAt: fetchedAt,
+	}
+	f.mu.Unlock()
+
+	// A failing archive must not keep the current document from being served
+	if f.archive != nil {
+		if err := f.archive.Store(year, data, header, fetchedAt); err != nil {
+			fmt.Printf("Error archiving revision of %d: %v\n", year, err)
+		}
+	}
+
+	return data, nil
+}
+
+// GetOathSchedule retrieves an oath scheduling document, a PDF or an HTML page
+func (f *httpFetcher) GetOathSchedule(url string) ([]byte, error) {
+	if data, found := f.cache.Get(url); found {
+		return data, nil
+	}
+
+	data, _, err := f.downloadFileWithRetry(url, 3, "application/pdf", "text/html")
 	if err != nil {
 		return nil, fmt.Errorf("failed to download file: %w", err)
 	}
@@ -77,11 +161,22 @@ func (f *httpFetcher) GetFile(year int) ([]byte, error) {
 	f.cache.Set(url, data)
 	f.cache.Cleanup()
 
+	hash := sha256.Sum256(data)
+	f.mu.Lock()
+	f.oathRevisions[url] = Source{
+		Kind:      KindOath,
+		URL:       url,
+		Hash:      hex.EncodeToString(hash[:]),
+		Size:      len(data),
+		FetchedAt: time.Now(),
+	}
+	f.mu.Unlock()
+
 	return data, nil
 }
 
 // downloadFileWithRetry handles the download with retry logic
-func (f *httpFetcher) downloadFileWithRetry(url string, maxRetries int) ([]byte, error) {
+func (f *httpFetcher) downloadFileWithRetry(url string, maxRetries int, contentTypes ...string) ([]byte, http.Header, error) {
 	var lastErr error
 
 	for i := range maxRetries {
@@ -89,22 +184,22 @@ func (f *httpFetcher) downloadFileWithRetry(url string, maxRetries int) ([]byte,
 			time.Sleep(time.Second * time.Duration(i*i)) // Exponential backoff
 		}
 
-		data, err := f.downloadFile(url)
+		data, header, err := f.downloadFile(url, contentTypes)
 		if err == nil {
-			return data, nil
+			return data, header, nil
 		}
 
 		lastErr = err
 	}
 
-	return nil, fmt.Errorf("after %d attempts: %w", maxRetries, lastErr)
+	return nil, nil, fmt.Errorf("after %d attempts: %w", maxRetries, lastErr)
 }
 
-// downloadFile handles a single download attempt
-func (f *httpFetcher) downloadFile(url string) ([]byte, error) {
+// downloadFile handles a single download attempt, accepting only the given content types
+func (f *httpFetcher) downloadFile(url string, contentTypes []string) ([]byte, http.Header, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("request creation failed: %w", err)
+		return nil, nil, fmt.Errorf("request creation failed: %w", err)
 	}
 
 	// Set optimized headers
@@ -117,7 +212,7 @@ func (f *httpFetcher) downloadFile(url string) ([]byte, error) {
 
 	resp, err := f.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -125,12 +220,17 @@ func (f *httpFetcher) downloadFile(url string) ([]byte, error) {
 		// Optimized error body reading
 		buf := make([]byte, 1024)
 		n, _ := io.ReadFull(resp.Body, buf)
-		return nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(buf[:n]))
+		return nil, nil, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(buf[:n]))
 	}
 
 	// Fast content type check
-	if ct := resp.Header.Get("Content-Type"); !strings.Contains(ct, "application/pdf") {
-		return nil, fmt.Errorf("unexpected content type: %s", ct)
+	ct := resp.Header.Get("Content-Type")
+	accepted := false
+	for _, contentType := range contentTypes {
+		accepted = accepted || strings.Contains(ct, contentType)
+	}
+	if !accepted {
+		return nil, nil, fmt.Errorf("unexpected content type: %s", ct)
 	}
 
 	// Optimized reading based on content length
@@ -138,11 +238,12 @@ func (f *httpFetcher) downloadFile(url string) ([]byte, error) {
 		// Pre-allocate exact buffer size
 		buf := make([]byte, resp.ContentLength)
 		_, err := io.ReadFull(resp.Body, buf)
-		return buf, err
+		return buf, resp.Header, err
 	}
 
 	// Fallback for unknown size - uses sync.Pool for buffers
-	return readWithPool(resp.Body)
+	data, err := readWithPool(resp.Body)
+	return data, resp.Header, err
 }
 
 // Reusable buffer pool for unknown content lengths
@@ -171,7 +272,57 @@ func readWithPool(r io.Reader) ([]byte, error) {
 	}
 }
 
+// Sources lists the supported years with their URL and last downloaded revision
+func (f *httpFetcher) Sources() []Source {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	sources := make([]Source, 0, len(supportedYears))
+	for year, filename := range supportedYears {
+		source, ok := f.revisions[year]
+		if !ok {
+			source = Source{Kind: KindOrders, Year: year, URL: f.baseURL + filename}
+		}
+		_, source.Cached = f.cache.Get(source.URL)
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Year < sources[j].Year
+	})
+
+	return sources
+}
+
+// OathSources lists the oath scheduling documents and their last downloaded revision
+func (f *httpFetcher) OathSources() []Source {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	sources := make([]Source, 0, len(f.oathURLs))
+	for _, url := range f.oathURLs {
+		source, ok := f.oathRevisions[url]
+		if !ok {
+			source = Source{Kind: KindOath, URL: url}
+		}
+		_, source.Cached = f.cache.Get(url)
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+// loadOathScheduleURLs reads the comma separated OATH_SCHEDULE_URLS environment variable
+func loadOathScheduleURLs() []string {
+	var urls []string
+	for _, field := range strings.Split(os.Getenv("OATH_SCHEDULE_URLS"), ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			urls = append(urls, field)
+		}
+	}
+	return urls
+}
+
+// CleanUpCache drops every cached file so the next request downloads it again
 func (f *httpFetcher) CleanUpCache() error {
-	f.cache.Cleanup()
+	f.cache.Clear()
 	return nil
 }
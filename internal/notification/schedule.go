@@ -0,0 +1,40 @@
+package notification
+
+import (
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+)
+
+// DeliveryTime returns when a notification generated at now should reach the
+// chat. The second result is false when it can be sent right away.
+func DeliveryTime(profile *database.Profile, now time.Time) (time.Time, bool) {
+	local := now.In(profile.Location())
+
+	if profile.DigestEnabled {
+		return nextHour(local, profile.DigestHour), true
+	}
+	if profile.QuietHoursEnabled && InQuietHours(profile, now) {
+		return nextHour(local, profile.QuietEnd), true
+	}
+	return now, false
+}
+
+// InQuietHours reports whether now falls inside the chat's quiet hours,
+// which may span midnight
+func InQuietHours(profile *database.Profile, now time.Time) bool {
+	hour := now.In(profile.Location()).Hour()
+	if profile.QuietStart < profile.QuietEnd {
+		return hour >= profile.QuietStart && hour < profile.QuietEnd
+	}
+	return hour >= profile.QuietStart || hour < profile.QuietEnd
+}
+
+// nextHour returns the next time the local clock shows the given hour
+func nextHour(local time.Time, hour int) time.Time {
+	next := time.Date(local.Year(), local.Month(), local.Day(), hour, 0, 0, 0, local.Location())
+	if !next.After(local) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, hour, 0, 0, 0, local.Location())
+	}
+	return next
+}
@@ -0,0 +1,146 @@
+package notification
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+)
+
+func TestDeliveryTime(t *testing.T) {
+	bucharest, err := time.LoadLocation("Europe/Bucharest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	quiet := func(timezone string, start, end int) *database.Profile {
+		return &database.Profile{Timezone: timezone, QuietHoursEnabled: true, QuietStart: start, QuietEnd: end}
+	}
+	digest := func(timezone string, hour int) *database.Profile {
+		return &database.Profile{Timezone: timezone, DigestEnabled: true, DigestHour: hour}
+	}
+
+	tests := []struct {
+		name         string
+		profile      *database.Profile
+		now          time.Time
+		wantDeferred bool
+		want         time.Time
+	}{
+		{
+			name:    "no schedule",
+			profile: &database.Profile{},
+			now:     time.Date(2026, 3, 10, 23, 0, 0, 0, bucharest),
+		},
+		{
+			name:    "quiet hours disabled",
+			profile: &database.Profile{QuietStart: 22, QuietEnd: 8},
+			now:     time.Date(2026, 3, 10, 23, 0, 0, 0, bucharest),
+		},
+		{
+			name:    "before quiet hours",
+			profile: quiet("", 22, 8),
+			now:     time.Date(2026, 3, 10, 21, 59, 0, 0, bucharest),
+		},
+		{
+			name:         "quiet hours before midnight",
+			profile:      quiet("", 22, 8),
+			now:          time.Date(2026, 3, 10, 22, 0, 0, 0, bucharest),
+			wantDeferred: true,
+			want:         time.Date(2026, 3, 11, 8, 0, 0, 0, bucharest),
+		},
+		{
+			name:         "quiet hours after midnight",
+			profile:      quiet("", 22, 8),
+			now:          time.Date(2026, 3, 11, 3, 30, 0, 0, bucharest),
+			wantDeferred: true,
+			want:         time.Date(2026, 3, 11, 8, 0, 0, 0, bucharest),
+		},
+		{
+			name:    "quiet hours over",
+			profile: quiet("", 22, 8),
+			now:     time.Date(2026, 3, 11, 8, 0, 0, 0, bucharest),
+		},
+		{
+			name:         "quiet hours within a day",
+			profile:      quiet("", 13, 15),
+			now:          time.Date(2026, 3, 11, 14, 0, 0, 0, bucharest),
+			wantDeferred: true,
+			want:         time.Date(2026, 3, 11, 15, 0, 0, 0, bucharest),
+		},
+		{
+			name:         "quiet hours in the chat's timezone",
+			profile:      quiet("America/New_York", 22, 8),
+			now:          time.Date(2026, 3, 11, 5, 0, 0, 0, bucharest), // 23:00 in New York
+			wantDeferred: true,
+			want:         time.Date(2026, 3, 11, 8, 0, 0, 0, newYork),
+		},
+		{
+			name:    "awake in the chat's timezone",
+			profile: quiet("America/New_York", 22, 8),
+			now:     time.Date(2026, 3, 11, 23, 0, 0, 0, bucharest), // 17:00 in New York
+		},
+		{
+			name:         "digest later today",
+			profile:      digest("", 9),
+			now:          time.Date(2026, 3, 11, 7, 0, 0, 0, bucharest),
+			wantDeferred: true,
+			want:         time.Date(2026, 3, 11, 9, 0, 0, 0, bucharest),
+		},
+		{
+			name:         "digest hour already passed",
+			profile:      digest("", 9),
+			now:          time.Date(2026, 3, 11, 9, 0, 0, 0, bucharest),
+			wantDeferred: true,
+			want:         time.Date(2026, 3, 12, 9, 0, 0, 0, bucharest),
+		},
+		{
+			name:         "digest takes precedence over quiet hours",
+			profile:      &database.Profile{DigestEnabled: true, DigestHour: 18, QuietHoursEnabled: true, QuietStart: 22, QuietEnd: 8},
+			now:          time.Date(2026, 3, 11, 23, 0, 0, 0, bucharest),
+			wantDeferred: true,
+			want:         time.Date(2026, 3, 12, 18, 0, 0, 0, bucharest),
+		},
+		{
+			name:         "digest in the chat's timezone",
+			profile:      digest("America/New_York", 9),
+			now:          time.Date(2026, 3, 11, 15, 0, 0, 0, bucharest), // 09:00 in New York
+			wantDeferred: true,
+			want:         time.Date(2026, 3, 12, 9, 0, 0, 0, newYork),
+		},
+		{
+			name:         "digest across a daylight saving change",
+			profile:      digest("", 9),
+			now:          time.Date(2026, 3, 28, 12, 0, 0, 0, bucharest),
+			wantDeferred: true,
+			want:         time.Date(2026, 3, 29, 9, 0, 0, 0, bucharest),
+		},
+		{
+			name:         "unknown timezone falls back to the default",
+			profile:      quiet("Nowhere/City", 22, 8),
+			now:          time.Date(2026, 3, 10, 23, 0, 0, 0, bucharest),
+			wantDeferred: true,
+			want:         time.Date(2026, 3, 11, 8, 0, 0, 0, bucharest),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, deferred := DeliveryTime(tt.profile, tt.now)
+			if deferred != tt.wantDeferred {
+				t.Fatalf("deferred = %v, want %v", deferred, tt.wantDeferred)
+			}
+			want := tt.want
+			if !tt.wantDeferred {
+				want = tt.now
+			}
+			if !got.Equal(want) {
+				t.Errorf("delivery at %s, want %s", got, want)
+			}
+		})
+	}
+}
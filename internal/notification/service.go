@@ -0,0 +1,230 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/go-telegram/bot"
+)
+
+const (
+	errorGettingProfile      = "error getting profile: %w"
+	errorQueueingMessage     = "error queueing notification: %w"
+	errorGettingNotification = "error getting pending notifications: %w"
+	digestSeparator          = "\n\n➖➖➖➖➖\n\n"
+	digestHeader             = "📬 <b>Rezumatul notificărilor (%d)</b>"
+	digestContinued          = "📬 <b>Rezumatul notificărilor (continuare)</b>"
+
+	// maxMessageLength is the longest message text Telegram accepts
+	maxMessageLength = 4096
+)
+
+// Sender delivers a message to a chat
+type Sender interface {
+	SendMessage(ctx context.Context, chatID int64, text string) error
+}
+
+// Service delivers notifications according to each chat's schedule
+type Service interface {
+	// Notify sends the notification now, or queues it when the chat is in
+	// its quiet hours or receives a daily digest
+	Notify(ctx context.Context, chatID int64, text string) error
+	// DeliverDue sends the queued notifications whose time has come,
+	// gathering the notifications for the same chat into a digest
+	DeliverDue(ctx context.Context) error
+}
+
+type service struct {
+	notificationService database.NotificationService
+	profileService      database.ProfileService
+	sender              Sender
+}
+
+// NewService creates a new instance of the notification service
+func NewService(notificationService database.NotificationService, profileService database.ProfileService, sender Sender) Service {
+	return &service{
+		notificationService: notificationService,
+		profileService:      profileService,
+		sender:              sender,
+	}
+}
+
+func (s *service) Notify(ctx context.Context, chatID int64, text string) error {
+	profile, err := s.profileService.GetProfile(chatID)
+	if err != nil {
+		return fmt.Errorf(errorGettingProfile, err)
+	}
+
+	deliverAt, deferred := DeliveryTime(profile, time.Now())
+	if !deferred {
+		return s.sender.SendMessage(ctx, chatID, text)
+	}
+
+	if err := s.notificationService.QueueNotification(chatID, text, deliverAt); err != nil {
+		return fmt.Errorf(errorQueueingMessage, err)
+	}
+	fmt.Printf("Deferred notification for chat %d until %s\n", chatID, deliverAt.Format(time.RFC3339))
+	return nil
+}
+
+func (s *service) DeliverDue(ctx context.Context) error {
+	notifications, err := s.notificationService.GetDueNotifications(time.Now())
+	if err != nil {
+		return fmt.Errorf(errorGettingNotification, err)
+	}
+
+	// Notifications are ordered by chat, so each chat's batch is contiguous
+	for start := 0; start < len(notifications); {
+		end := start
+		for end < len(notifications) && notifications[end].ChatID == notifications[start].ChatID {
+			end++
+		}
+		s.deliverBatch(ctx, notifications[start:end])
+		start = end
+	}
+	return nil
+}
+
+// deliverBatch sends a chat's queued notifications, gathered into as few
+// messages as Telegram accepts, and removes each notification once the
+// message completing it was sent. Failed deliveries are kept for the next
+// run, except for chats that blocked the bot and messages Telegram rejects,
+// which would fail again on every run.
+func (s *service) deliverBatch(ctx context.Context, notifications []database.PendingNotification) {
+	chatID := notifications[0].ChatID
+	parts := formatBatch(notifications)
+
+	for i, part := range parts {
+		err := s.sender.SendMessage(ctx, chatID, part.text)
+		if errors.Is(err, bot.ErrorForbidden) || errors.Is(err, bot.ErrorBadRequest) {
+			var ids []uint
+			for _, remaining := range parts[i:] {
+				ids = append(ids, remaining.ids...)
+			}
+			fmt.Printf("Dropping %d notifications for chat %d: %v\n", len(ids), chatID, err)
+			s.deleteNotifications(chatID, ids)
+			return
+		}
+		if err != nil {
+			fmt.Printf("Error delivering notifications to chat %d, %d of %d messages sent: %v\n", chatID, i, len(parts), err)
+			return
+		}
+		if !s.deleteNotifications(chatID, part.ids) {
+			return
+		}
+	}
+	fmt.Printf("Delivered %d deferred notifications to chat %d in %d messages\n", len(notifications), chatID, len(parts))
+}
+
+func (s *service) deleteNotifications(chatID int64, ids []uint) bool {
+	if len(ids) == 0 {
+		return true
+	}
+	if err := s.notificationService.DeleteNotifications(ids); err != nil {
+		fmt.Printf("Error removing delivered notifications for chat %d: %v\n", chatID, err)
+		return false
+	}
+	return true
+}
+
+// digestPart is a message of a digest with the notifications it completes
+type digestPart struct {
+	text string
+	ids  []uint
+}
+
+// formatBatch gathers a chat's notifications into messages under
+// maxMessageLength. A notification too long for one message is split at
+// line breaks and completed by the message holding its last piece.
+func formatBatch(notifications []database.PendingNotification) []digestPart {
+	if len(notifications) == 1 {
+		pieces := splitText(notifications[0].Text, maxMessageLength)
+		parts := make([]digestPart, len(pieces))
+		for i, piece := range pieces {
+			parts[i].text = piece
+		}
+		parts[len(parts)-1].ids = []uint{notifications[0].ID}
+		return parts
+	}
+
+	header := fmt.Sprintf(digestHeader, len(notifications))
+	pieceLimit := maxMessageLength - max(textLength(header), textLength(digestContinued)) - textLength(digestSeparator)
+
+	var parts []digestPart
+	current := digestPart{text: header}
+	for _, notification := range notifications {
+		for _, piece := range splitText(notification.Text, pieceLimit) {
+			if textLength(current.text)+textLength(digestSeparator)+textLength(piece) > maxMessageLength {
+				parts = append(parts, current)
+				current = digestPart{text: digestContinued}
+			}
+			current.text += digestSeparator + piece
+		}
+		current.ids = append(current.ids, notification.ID)
+	}
+	return append(parts, current)
+}
+
+// splitText cuts text into pieces of at most limit, at line breaks when
+// possible. Notifications keep their HTML tags within a line, so pieces
+// stay well-formed.
+func splitText(text string, limit int) []string {
+	if textLength(text) <= limit {
+		return []string{text}
+	}
+
+	var pieces []string
+	var current string
+	for _, line := range strings.Split(text, "\n") {
+		for textLength(line) > limit {
+			// A single line longer than a message is cut between characters
+			head, tail := cutAt(line, limit)
+			if current != "" {
+				pieces = append(pieces, current)
+				current = ""
+			}
+			pieces = append(pieces, head)
+			line = tail
+		}
+		switch {
+		case current == "":
+			current = line
+		case textLength(current)+1+textLength(line) > limit:
+			pieces = append(pieces, current)
+			current = line
+		default:
+			current += "\n" + line
+		}
+	}
+	if current != "" {
+		pieces = append(pieces, current)
+	}
+	return pieces
+}
+
+// textLength counts UTF-16 code units like Telegram does. The HTML tags are
+// counted too, so the length Telegram measures after parsing them is lower.
+func textLength(text string) int {
+	length := 0
+	for _, r := range text {
+		length += utf16.RuneLen(r)
+	}
+	return length
+}
+
+// cutAt splits text after limit UTF-16 code units, between characters
+func cutAt(text string, limit int) (string, string) {
+	length := 0
+	for i, r := range text {
+		if length+utf16.RuneLen(r) > limit {
+			return text[:i], text[i:]
+		}
+		length += utf16.RuneLen(r)
+	}
+	return text, ""
+}
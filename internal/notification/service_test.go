@@ -0,0 +1,172 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/go-telegram/bot"
+)
+
+func pending(id uint, text string) database.PendingNotification {
+	return database.PendingNotification{ID: id, ChatID: 1, Text: text}
+}
+
+// notification builds a text of the given length out of short lines
+func notification(length int) string {
+	line := "🔔 <b>123/RD/2023</b> rezolvat"
+	var b strings.Builder
+	for textLength(b.String())+textLength(line)+1 <= length {
+		b.WriteString(line + "\n")
+	}
+	return b.String() + strings.Repeat("x", length-textLength(b.String()))
+}
+
+func checkParts(t *testing.T, parts []digestPart) {
+	t.Helper()
+	for i, part := range parts {
+		if length := textLength(part.text); length > maxMessageLength {
+			t.Errorf("message %d is %d characters long", i, length)
+		}
+	}
+}
+
+func TestFormatBatch(t *testing.T) {
+	t.Run("single notification is sent as is", func(t *testing.T) {
+		parts := formatBatch([]database.PendingNotification{pending(1, "salut")})
+		if len(parts) != 1 || parts[0].text != "salut" || !reflect.DeepEqual(parts[0].ids, []uint{1}) {
+			t.Errorf("got %+v", parts)
+		}
+	})
+
+	t.Run("short notifications share a message", func(t *testing.T) {
+		parts := formatBatch([]database.PendingNotification{pending(1, "unu"), pending(2, "doi")})
+		want := fmt.Sprintf(digestHeader, 2) + digestSeparator + "unu" + digestSeparator + "doi"
+		if len(parts) != 1 || parts[0].text != want || !reflect.DeepEqual(parts[0].ids, []uint{1, 2}) {
+			t.Errorf("got %+v", parts)
+		}
+	})
+
+	t.Run("long digest is split", func(t *testing.T) {
+		var notifications []database.PendingNotification
+		for id := uint(1); id <= 12; id++ {
+			notifications = append(notifications, pending(id, notification(1500)))
+		}
+		parts := formatBatch(notifications)
+		checkParts(t, parts)
+		if len(parts) < 5 {
+			t.Errorf("got %d messages for 18000 characters", len(parts))
+		}
+
+		var ids []uint
+		for i, part := range parts {
+			ids = append(ids, part.ids...)
+			if i > 0 && !strings.HasPrefix(part.text, digestContinued) {
+				t.Errorf("message %d does not start with the continuation header", i)
+			}
+		}
+		if len(ids) != 12 {
+			t.Errorf("messages complete %v, want every notification once", ids)
+		}
+	})
+
+	t.Run("notification longer than a message", func(t *testing.T) {
+		long := notification(9000)
+		parts := formatBatch([]database.PendingNotification{pending(1, "scurt"), pending(2, long), pending(3, "final")})
+		checkParts(t, parts)
+
+		var joined []string
+		for _, part := range parts {
+			joined = append(joined, part.text)
+		}
+		if text := strings.Join(joined, ""); !strings.Contains(text, "scurt") || !strings.Contains(text, "final") {
+			t.Error("short notifications are missing")
+		}
+		// 2 is completed by the message holding its last line, not before
+		for _, part := range parts {
+			for _, id := range part.ids {
+				if id == 2 && !strings.Contains(part.text, "xxx") {
+					t.Errorf("notification 2 completed by %q", part.text[:40])
+				}
+			}
+		}
+	})
+
+	t.Run("single line longer than a message", func(t *testing.T) {
+		parts := formatBatch([]database.PendingNotification{pending(1, strings.Repeat("ș", 5000))})
+		checkParts(t, parts)
+		if len(parts) != 2 || parts[0].ids != nil || !reflect.DeepEqual(parts[1].ids, []uint{1}) {
+			t.Errorf("got %d messages completing %v", len(parts), parts[len(parts)-1].ids)
+		}
+	})
+
+	t.Run("characters outside the BMP count twice", func(t *testing.T) {
+		parts := formatBatch([]database.PendingNotification{pending(1, strings.Repeat("📬", 3000))})
+		checkParts(t, parts)
+		if len(parts) != 2 {
+			t.Errorf("got %d messages, want 2", len(parts))
+		}
+	})
+}
+
+type fakeSender struct {
+	sent []string
+	// fail returns the error for the nth message sent, starting at 0
+	fail map[int]error
+}
+
+func (f *fakeSender) SendMessage(ctx context.Context, chatID int64, text string) error {
+	n := len(f.sent)
+	f.sent = append(f.sent, text)
+	return f.fail[n]
+}
+
+type fakeNotifications struct {
+	database.NotificationService
+	deleted []uint
+}
+
+func (f *fakeNotifications) DeleteNotifications(ids []uint) error {
+	f.deleted = append(f.deleted, ids...)
+	return nil
+}
+
+func TestDeliverBatch(t *testing.T) {
+	// Two notifications of 3000 characters do not fit in one message
+	notifications := []database.PendingNotification{pending(1, notification(3000)), pending(2, notification(3000))}
+
+	tests := []struct {
+		name        string
+		fail        map[int]error
+		wantSent    int
+		wantDeleted []uint
+	}{
+		{name: "delivered", wantSent: 2, wantDeleted: []uint{1, 2}},
+		{name: "transient error kept", fail: map[int]error{0: fmt.Errorf("timeout")}, wantSent: 1},
+		{name: "transient error on the second message", fail: map[int]error{1: fmt.Errorf("timeout")}, wantSent: 2, wantDeleted: []uint{1}},
+		{name: "blocked chat dropped", fail: map[int]error{0: bot.ErrorForbidden}, wantSent: 1, wantDeleted: []uint{1, 2}},
+		{name: "rejected message dropped", fail: map[int]error{0: fmt.Errorf("%w, message is too long", bot.ErrorBadRequest)}, wantSent: 1, wantDeleted: []uint{1, 2}},
+		{name: "rejected second message", fail: map[int]error{1: bot.ErrorBadRequest}, wantSent: 2, wantDeleted: []uint{1, 2}},
+		{name: "rate limit kept", fail: map[int]error{0: bot.ErrorTooManyRequests}, wantSent: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := &fakeSender{fail: tt.fail}
+			store := &fakeNotifications{}
+			s := &service{notificationService: store, sender: sender}
+
+			s.deliverBatch(context.Background(), notifications)
+
+			if len(sender.sent) != tt.wantSent {
+				t.Errorf("sent %d messages, want %d", len(sender.sent), tt.wantSent)
+			}
+			if !reflect.DeepEqual(store.deleted, tt.wantDeleted) {
+				t.Errorf("deleted %v, want %v", store.deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
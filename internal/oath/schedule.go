@@ -0,0 +1,214 @@
+package oath
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/ledongthuc/pdf"
+)
+
+// rowWindow is how far after a dossier identifier, on the same line, a date
+// still belongs to its row
+const rowWindow = 80
+
+var (
+	dossierPattern = regexp.MustCompile(`(\d+)\s*/\s*RD\s*/\s*(\d{4})`)
+	// datePattern matches a ceremony date, optionally followed by its hour,
+	// e.g. "12.11.2025, ora 10:00"
+	datePattern = regexp.MustCompile(`(\d{2})\.(\d{2})\.(\d{4})(?:[^\d\n]{0,12}?(\d{1,2})[:.](\d{2}))?`)
+	// locationPattern matches a ceremony location introduced by its label
+	locationPattern = regexp.MustCompile(`(?i)(?:loca[țţt]i[ae]|locul|adresa|sediul)\s*:\s*([^\n]{3,120})`)
+
+	tagPattern       = regexp.MustCompile(`(?s)<[^>]*>`)
+	blockPattern     = regexp.MustCompile(`(?is)<(script|style)[^>]*>.*?</(script|style)>`)
+	lineBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</(p|tr|li|div|h\d)>`)
+	cellPattern      = regexp.MustCompile(`(?i)</t[dh]>`)
+
+	// ceremonyLocation is the timezone the ceremonies are scheduled in
+	ceremonyLocation = loadCeremonyLocation()
+)
+
+// Appointment is an oath ceremony scheduled for a dossier
+type Appointment struct {
+	Number dossier.Number
+	// Date is the day of the ceremony, with its hour when HasTime is set
+	Date     time.Time
+	HasTime  bool
+	Location string
+	// Source is the URL of the scheduling document
+	Source string
+}
+
+// token is a dossier, date or location found in a scheduling document
+type token struct {
+	start, end int
+	number     *dossier.Number
+	date       *time.Time
+	hasTime    bool
+	location   string
+}
+
+// ParseSchedule extracts the appointments of an oath scheduling document, a
+// PDF or an HTML page. The lists are published in two layouts: a heading
+// with the date and location followed by the dossiers, or a row per dossier
+// with its own date; both are recognized.
+func ParseSchedule(data []byte, source string) ([]Appointment, error) {
+	var text string
+	if bytes.HasPrefix(data, []byte("%PDF")) {
+		extracted, err := pdfText(data)
+		if err != nil {
+			return nil, err
+		}
+		text = extracted
+	} else {
+		text = htmlText(string(data))
+	}
+	return parseText(text, source), nil
+}
+
+func parseText(text, source string) []Appointment {
+	tokens := tokenize(text)
+
+	var appointments []Appointment
+	var current Appointment
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok.location != "":
+			current.Location = tok.location
+		case tok.date != nil:
+			current.Date, current.HasTime = *tok.date, tok.hasTime
+		case tok.number != nil:
+			appointment := current
+			appointment.Number = *tok.number
+			appointment.Source = source
+
+			// A date (and location) on the dossier's own row overrides the
+			// heading for that row only
+			for i+1 < len(tokens) {
+				next := tokens[i+1]
+				if next.number != nil || next.start-tok.end > rowWindow || strings.Contains(text[tok.end:next.start], "\n") {
+					break
+				}
+				if next.date != nil {
+					appointment.Date, appointment.HasTime = *next.date, next.hasTime
+				}
+				if next.location != "" {
+					appointment.Location = next.location
+				}
+				i++
+			}
+
+			if !appointment.Date.IsZero() {
+				appointments = append(appointments, appointment)
+			}
+		}
+	}
+	return appointments
+}
+
+// tokenize finds the dossiers, dates and locations of text in order of appearance
+func tokenize(text string) []token {
+	var tokens []token
+	for _, idx := range dossierPattern.FindAllStringSubmatchIndex(text, -1) {
+		value, _ := strconv.Atoi(text[idx[2]:idx[3]])
+		year, _ := strconv.Atoi(text[idx[4]:idx[5]])
+		number := dossier.Number{Value: value, Year: year}
+		tokens = append(tokens, token{start: idx[0], end: idx[1], number: &number})
+	}
+	for _, idx := range datePattern.FindAllStringSubmatchIndex(text, -1) {
+		date, hasTime, ok := parseDate(text, idx)
+		if !ok {
+			continue
+		}
+		tokens = append(tokens, token{start: idx[0], end: idx[1], date: &date, hasTime: hasTime})
+	}
+	for _, idx := range locationPattern.FindAllStringSubmatchIndex(text, -1) {
+		location := cleanLocation(text[idx[2]:idx[3]])
+		if location == "" {
+			continue
+		}
+		tokens = append(tokens, token{start: idx[0], end: idx[1], location: location})
+	}
+
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].start < tokens[j].start })
+	return tokens
+}
+
+// parseDate reads a datePattern match; ok is false for impossible dates
+func parseDate(text string, idx []int) (date time.Time, hasTime bool, ok bool) {
+	group := func(i int) int {
+		if idx[2*i] < 0 {
+			return -1
+		}
+		n, _ := strconv.Atoi(text[idx[2*i]:idx[2*i+1]])
+		return n
+	}
+	day, month, year := group(1), group(2), group(3)
+	if month < 1 || month > 12 || day < 1 || day > 31 {
+		return time.Time{}, false, false
+	}
+	hour, minute := group(4), group(5)
+	hasTime = hour >= 0 && hour < 24 && minute >= 0 && minute < 60
+	if !hasTime {
+		hour, minute = 0, 0
+	}
+	return time.Date(year, time.Month(month), day, hour, minute, 0, 0, ceremonyLocation), hasTime, true
+}
+
+// cleanLocation stops a location before the next dossier or date on its line
+func cleanLocation(location string) string {
+	if idx := dossierPattern.FindStringIndex(location); idx != nil {
+		location = location[:idx[0]]
+	}
+	if idx := datePattern.FindStringIndex(location); idx != nil {
+		location = location[:idx[0]]
+	}
+	return strings.Trim(strings.TrimSpace(location), ",;")
+}
+
+func pdfText(data []byte) (string, error) {
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("error creating PDF reader: %v", err)
+	}
+
+	var text strings.Builder
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		pageText, err := page.GetPlainText(nil)
+		if err != nil {
+			return "", fmt.Errorf("error reading page %d: %v", i, err)
+		}
+		text.WriteString(pageText)
+		text.WriteString("\n")
+	}
+	return text.String(), nil
+}
+
+// htmlText reduces an HTML page to its text, a line per paragraph or table row
+func htmlText(page string) string {
+	page = blockPattern.ReplaceAllString(page, "")
+	page = lineBreakPattern.ReplaceAllString(page, "\n")
+	page = cellPattern.ReplaceAllString(page, " ")
+	page = tagPattern.ReplaceAllString(page, "")
+	return html.UnescapeString(page)
+}
+
+func loadCeremonyLocation() *time.Location {
+	location, err := time.LoadLocation("Europe/Bucharest")
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
@@ -0,0 +1,172 @@
+package oath
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/andiq123/cetatenie-analyzer/internal/fetcher"
+)
+
+type wantAppointment struct {
+	number   dossier.Number
+	date     string
+	hasTime  bool
+	location string
+}
+
+func checkAppointments(t *testing.T, got []Appointment, want []wantAppointment) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d appointments, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		a := got[i]
+		if a.Number != w.number {
+			t.Errorf("appointment %d: number %s, want %s", i, a.Number, w.number)
+		}
+		if date := a.Date.Format("02.01.2006 15:04"); date != w.date {
+			t.Errorf("appointment %d (%s): date %s, want %s", i, a.Number, date, w.date)
+		}
+		if a.HasTime != w.hasTime {
+			t.Errorf("appointment %d (%s): HasTime %v, want %v", i, a.Number, a.HasTime, w.hasTime)
+		}
+		if a.Location != w.location {
+			t.Errorf("appointment %d (%s): location %q, want %q", i, a.Number, a.Location, w.location)
+		}
+		if a.Source != "https://example.org/lista" {
+			t.Errorf("appointment %d (%s): source %q", i, a.Number, a.Source)
+		}
+		if a.Date.Location() != ceremonyLocation {
+			t.Errorf("appointment %d (%s): time zone %s, want %s", i, a.Number, a.Date.Location(), ceremonyLocation)
+		}
+	}
+}
+
+func number(value, year int) dossier.Number {
+	return dossier.Number{Value: value, Year: year}
+}
+
+func TestParseScheduleHeadingLayout(t *testing.T) {
+	text := "PROGRAMARE DEPUNERE JURĂMÂNT\n" +
+		"Data: 12.11.2026, ora 10:00\n" +
+		"Locația: Sediul ANC, Str. Smârdan 5, București\n" +
+		"1. 123/RD/2023 POPESCU ION\n" +
+		"2. 124 / RD / 2023 IONESCU MARIA\n" +
+		"Data: 13.11.2026\n" +
+		"Adresa: Consulatul General Chișinău\n" +
+		"3. 55/RD/2022 RUSU ANA\n"
+
+	appointments, err := ParseSchedule([]byte(text), "https://example.org/lista")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkAppointments(t, appointments, []wantAppointment{
+		{number(123, 2023), "12.11.2026 10:00", true, "Sediul ANC, Str. Smârdan 5, București"},
+		{number(124, 2023), "12.11.2026 10:00", true, "Sediul ANC, Str. Smârdan 5, București"},
+		{number(55, 2022), "13.11.2026 00:00", false, "Consulatul General Chișinău"},
+	})
+}
+
+func TestParseScheduleRowLayout(t *testing.T) {
+	text := "Nr. dosar Data și ora Locul\n" +
+		"123/RD/2023 14.11.2026 ora 09:30 Locul: Sala 1\n" +
+		"124/RD/2023 15.11.2026 11.00\n" +
+		"125/RD/2023\n"
+
+	appointments, err := ParseSchedule([]byte(text), "https://example.org/lista")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 125 has no date on its row and there is no heading: it is not scheduled
+	checkAppointments(t, appointments, []wantAppointment{
+		{number(123, 2023), "14.11.2026 09:30", true, "Sala 1"},
+		{number(124, 2023), "15.11.2026 11:00", true, ""},
+	})
+}
+
+func TestParseScheduleHTML(t *testing.T) {
+	page := `<!DOCTYPE html><html><head><style>td { color: red }</style>
+<script>var d = "01.01.2020 99/RD/2020";</script></head><body>
+<h2>Programare depunere jur&#259;m&acirc;nt 12.11.2026, ora 10:00, Loca&#539;ia: Sediul ANC</h2>
+<table>
+<tr><th>Dosar</th><th>Nume</th><th>Data</th></tr>
+<tr><td>123/RD/2023</td><td>Ion</td></tr>
+<tr><td>124/RD/2023</td><td>Maria</td><td>14.11.2026 ora 12:30</td></tr>
+</table>
+<h2>Programare 20.11.2026</h2><p>Adresa: Consulatul Chi&#537;in&#259;u</p>
+<p>55/RD/2022<br>56/RD/2022</p>
+</body></html>`
+
+	appointments, err := ParseSchedule([]byte(page), "https://example.org/lista")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The script is ignored; a row date overrides the heading for its row only
+	checkAppointments(t, appointments, []wantAppointment{
+		{number(123, 2023), "12.11.2026 10:00", true, "Sediul ANC"},
+		{number(124, 2023), "14.11.2026 12:30", true, "Sediul ANC"},
+		{number(55, 2022), "20.11.2026 00:00", false, "Consulatul Chișinău"},
+		{number(56, 2022), "20.11.2026 00:00", false, "Consulatul Chișinău"},
+	})
+}
+
+func TestParseScheduleInvalidPDF(t *testing.T) {
+	if _, err := ParseSchedule([]byte("%PDF-1.4 truncated"), "https://example.org/lista"); err == nil {
+		t.Error("ParseSchedule accepted a broken PDF")
+	}
+}
+
+// scheduleFetcher serves the scheduling documents by URL, failing the missing ones
+type scheduleFetcher struct {
+	fetcher.FileFetcher
+	documents map[string]string
+	urls      []string
+}
+
+func (f *scheduleFetcher) OathSources() []fetcher.Source {
+	sources := make([]fetcher.Source, len(f.urls))
+	for i, url := range f.urls {
+		sources[i] = fetcher.Source{Kind: fetcher.KindOath, URL: url}
+	}
+	return sources
+}
+
+func (f *scheduleFetcher) GetOathSchedule(url string) ([]byte, error) {
+	document, ok := f.documents[url]
+	if !ok {
+		return nil, errors.New("timeout")
+	}
+	return []byte(document), nil
+}
+
+func TestAppointments(t *testing.T) {
+	f := &scheduleFetcher{
+		urls: []string{"a", "b", "c"},
+		documents: map[string]string{
+			"a": "Data: 12.11.2026\n123/RD/2023\n124/RD/2023\n",
+			// A later list reschedules 124
+			"b": "Data: 19.11.2026\n124/RD/2023\n",
+		},
+	}
+
+	schedule, err := NewService(f).Appointments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schedule.Complete() || len(schedule.Failed) != 1 || schedule.Failed[0] != "c" {
+		t.Errorf("Failed = %v, want [c]", schedule.Failed)
+	}
+	if got := schedule.Appointments[number(124, 2023)].Date; !got.Equal(time.Date(2026, 11, 19, 0, 0, 0, 0, ceremonyLocation)) {
+		t.Errorf("124/RD/2023 scheduled on %s, want the latest list", got)
+	}
+	if len(schedule.Appointments) != 2 {
+		t.Errorf("got %d appointments, want 2", len(schedule.Appointments))
+	}
+
+	f.documents = nil
+	if _, err := NewService(f).Appointments(); err == nil {
+		t.Error("Appointments succeeded although no list could be read")
+	}
+}
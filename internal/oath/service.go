@@ -0,0 +1,83 @@
+// Package oath reads the lists scheduling the oath ceremonies (depunerea
+// jurământului) that follow the resolution of a dossier
+package oath
+
+import (
+	"fmt"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/andiq123/cetatenie-analyzer/internal/fetcher"
+)
+
+// Service defines the interface for reading the oath scheduling lists
+type Service interface {
+	// Enabled reports whether any scheduling document is configured
+	Enabled() bool
+	Appointments() (*Schedule, error)
+}
+
+// Schedule is what the scheduling documents list. Failed holds the URLs of
+// the documents that could not be read: their appointments are missing, so a
+// dossier absent from Appointments may still be scheduled.
+type Schedule struct {
+	Appointments map[dossier.Number]Appointment
+	Failed       []string
+}
+
+// Complete reports whether every scheduling document was read
+func (s *Schedule) Complete() bool {
+	return len(s.Failed) == 0
+}
+
+// service implements the Service interface
+type service struct {
+	fetcher fetcher.FileFetcher
+}
+
+// NewService creates a new instance of the oath scheduling service reading
+// the documents configured in the fetcher
+func NewService(fetcher fetcher.FileFetcher) Service {
+	return &service{fetcher: fetcher}
+}
+
+func (s *service) Enabled() bool {
+	return len(s.fetcher.OathSources()) > 0
+}
+
+// Appointments reads every scheduling document. A dossier listed in several
+// documents keeps its latest appointment, a rescheduling is published as a
+// new list. Documents that fail are skipped and reported in Failed; an error
+// is returned only if all of them did.
+func (s *service) Appointments() (*Schedule, error) {
+	schedule := &Schedule{Appointments: make(map[dossier.Number]Appointment)}
+	sources := s.fetcher.OathSources()
+
+	var lastErr error
+	for _, source := range sources {
+		data, err := s.fetcher.GetOathSchedule(source.URL)
+		if err != nil {
+			fmt.Printf("Error fetching oath schedule %s: %v\n", source.URL, err)
+			lastErr = err
+			schedule.Failed = append(schedule.Failed, source.URL)
+			continue
+		}
+		parsed, err := ParseSchedule(data, source.URL)
+		if err != nil {
+			fmt.Printf("Error parsing oath schedule %s: %v\n", source.URL, err)
+			lastErr = err
+			schedule.Failed = append(schedule.Failed, source.URL)
+			continue
+		}
+
+		for _, appointment := range parsed {
+			if existing, ok := schedule.Appointments[appointment.Number]; !ok || appointment.Date.After(existing.Date) {
+				schedule.Appointments[appointment.Number] = appointment
+			}
+		}
+	}
+
+	if len(sources) > 0 && len(schedule.Failed) == len(sources) {
+		return nil, fmt.Errorf("no oath schedule could be read: %w", lastErr)
+	}
+	return schedule, nil
+}
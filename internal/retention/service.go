@@ -0,0 +1,59 @@
+package retention
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+)
+
+// defaultRetentionDays is used when DATA_RETENTION_DAYS is not set
+const defaultRetentionDays = 365
+
+// Service defines the interface for the data retention job
+type Service interface {
+	PurgeExpiredData() error
+}
+
+// service implements the Service interface
+type service struct {
+	privacyService database.PrivacyService
+	period         time.Duration
+}
+
+// NewService creates a retention job keeping data for the number of days in
+// DATA_RETENTION_DAYS (365 by default)
+func NewService(privacyService database.PrivacyService) Service {
+	return &service{
+		privacyService: privacyService,
+		period:         loadPeriod(),
+	}
+}
+
+// PurgeExpiredData erases the chats, queued notifications and lookup history older than the retention period
+func (s *service) PurgeExpiredData() error {
+	report, err := s.privacyService.PurgeExpired(time.Now().Add(-s.period))
+	if err != nil {
+		return fmt.Errorf("error purging expired data: %w", err)
+	}
+
+	if report.Chats > 0 || report.Notifications > 0 || report.Lookups > 0 {
+		fmt.Printf("Retention: erased %d inactive chats, %d expired notifications and %d old lookups\n", report.Chats, report.Notifications, report.Lookups)
+	}
+	return nil
+}
+
+func loadPeriod() time.Duration {
+	days := defaultRetentionDays
+	if value := os.Getenv("DATA_RETENTION_DAYS"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			fmt.Printf("Ignoring invalid DATA_RETENTION_DAYS %q, using %d days\n", value, defaultRetentionDays)
+		} else {
+			days = parsed
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
@@ -0,0 +1,207 @@
+// Package revision records the revisions of the annual PDFs and what changed
+// between them
+package revision
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+)
+
+// Update is the outcome of tracking the current revision of a year
+type Update struct {
+	Revision database.Revision
+	// Changed is false when the revision was already recorded
+	Changed bool
+	// Diff lists the changes against the previous revision, nil for a baseline
+	Diff *decree.RevisionDiff
+}
+
+// Changes is what changed in a year's PDF since a consumer last processed it
+type Changes struct {
+	Year int
+	// Latest is the current revision, Advance moves the consumer's cursor to it
+	Latest database.Revision
+	// Changed is false when no revision was recorded since the cursor
+	Changed bool
+	// Complete is false when the changes cannot be composed from the recorded
+	// ones: the consumer has no cursor yet or a baseline was recorded since
+	Complete bool
+	// States maps every dossier that changed since the cursor to its current state
+	States map[string]decree.FindState
+}
+
+// Service defines the interface for revision tracking
+type Service interface {
+	Track(year int) (*Update, error)
+	ChangesSince(consumer string, year int) (*Changes, error)
+	Advance(consumer string, changes *Changes) error
+}
+
+// service implements the Service interface
+type service struct {
+	processor       decree.Processor
+	revisionService database.RevisionService
+	// mu keeps two checks from recording the same revision twice
+	mu sync.Mutex
+}
+
+// NewService creates a new instance of the revision tracking service
+func NewService(processor decree.Processor, revisionService database.RevisionService) Service {
+	return &service{
+		processor:       processor,
+		revisionService: revisionService,
+	}
+}
+
+// Track parses the current PDF of a year and, if its revision was not
+// recorded yet, stores it together with its diff against the previous one
+func (s *service) Track(year int) (*Update, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, err := s.processor.Document(year)
+	if err != nil {
+		return nil, fmt.Errorf("error loading document: %w", err)
+	}
+
+	latest, err := s.revisionService.GetLatestRevision(year)
+	if err != nil {
+		return nil, fmt.Errorf("error getting latest revision: %w", err)
+	}
+	if latest != nil && latest.Hash == doc.Revision {
+		return &Update{Revision: *latest}, nil
+	}
+
+	snapshot, err := doc.Snapshot()
+	if err != nil {
+		return nil, fmt.Errorf("error encoding snapshot: %w", err)
+	}
+	revision := database.Revision{
+		Year:     year,
+		Hash:     doc.Revision,
+		Entries:  len(doc.Entries),
+		Snapshot: snapshot,
+	}
+	if resolved := doc.Resolved(1, dossier.MaxNumber); len(resolved) > 0 {
+		revision.Resolved = len(resolved)
+		revision.HighestResolved = resolved[len(resolved)-1]
+	}
+
+	var diff *decree.RevisionDiff
+	var changes []database.RevisionChange
+	if latest != nil && latest.Snapshot != nil {
+		previous, err := decree.DocumentFromSnapshot(year, latest.Hash, latest.Snapshot)
+		if err != nil {
+			return nil, err
+		}
+		diff = decree.Diff(previous, doc)
+		changes = changeRows(diff)
+
+		revision.PreviousHash = latest.Hash
+		revision.Added = len(diff.Added)
+		revision.NewlyResolved = len(diff.NewlyResolved)
+		revision.Removed = len(diff.Removed)
+		revision.Changed = len(diff.Changed)
+	}
+
+	if err := s.revisionService.SaveRevision(&revision, changes); err != nil {
+		return nil, fmt.Errorf("error saving revision: %w", err)
+	}
+	fmt.Printf("Recorded revision %s of %d: %d added, %d resolved, %d removed, %d changed\n",
+		shortHash(revision.Hash), year, revision.Added, revision.NewlyResolved, revision.Removed, revision.Changed)
+
+	return &Update{Revision: revision, Changed: true, Diff: diff}, nil
+}
+
+// ChangesSince tracks the current revision of a year and composes every
+// change recorded after the consumer's cursor. Revisions recorded by other
+// consumers in the meantime are included, so none is missed.
+func (s *service) ChangesSince(consumer string, year int) (*Changes, error) {
+	update, err := s.Track(year)
+	if err != nil {
+		return nil, err
+	}
+	changes := &Changes{Year: year, Latest: update.Revision, States: make(map[string]decree.FindState)}
+
+	cursor, err := s.revisionService.GetCursor(consumer, year)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cursor: %w", err)
+	}
+	if cursor == nil {
+		changes.Changed = true
+		return changes, nil
+	}
+	if cursor.RevisionID == update.Revision.ID {
+		changes.Complete = true
+		return changes, nil
+	}
+
+	revisions, err := s.revisionService.GetRevisionsAfter(year, cursor.RevisionID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting revisions: %w", err)
+	}
+	changes.Changed = true
+	for _, revision := range revisions {
+		if revision.Baseline() {
+			return changes, nil
+		}
+	}
+	for _, revision := range revisions {
+		rows, err := s.revisionService.GetChanges(revision.ID)
+		if err != nil {
+			return nil, fmt.Errorf("error getting changes: %w", err)
+		}
+		// Revisions are oldest first, the last change of a dossier wins
+		for _, row := range rows {
+			changes.States[row.DecreeNumber] = rowState(row)
+		}
+	}
+	changes.Complete = true
+	return changes, nil
+}
+
+// Advance moves the consumer's cursor to the revision its changes were composed up to
+func (s *service) Advance(consumer string, changes *Changes) error {
+	if changes.Latest.ID == 0 {
+		return nil
+	}
+	return s.revisionService.SetCursor(consumer, changes.Year, changes.Latest.ID)
+}
+
+// rowState is the state of a dossier after a recorded change
+func rowState(row database.RevisionChange) decree.FindState {
+	if row.Kind == database.ChangeRemoved {
+		return decree.StateNotFound
+	}
+	return decree.Entry{Solution: row.NewSolution}.State()
+}
+
+// changeRows flattens a diff into the rows stored for a revision. Orders are
+// stored in their canonical form, the one orders are looked up by.
+func changeRows(diff *decree.RevisionDiff) []database.RevisionChange {
+	changes := make([]database.RevisionChange, 0, len(diff.Added)+len(diff.NewlyResolved)+len(diff.Removed)+len(diff.Changed))
+	for _, entry := range diff.Added {
+		changes = append(changes, database.RevisionChange{Kind: database.ChangeAdded, DecreeNumber: entry.Number.String(), Order: decree.CanonicalOrder(entry.Order), NewSolution: entry.Solution})
+	}
+	for _, entry := range diff.NewlyResolved {
+		changes = append(changes, database.RevisionChange{Kind: database.ChangeResolved, DecreeNumber: entry.Number.String(), Order: decree.CanonicalOrder(entry.Order), NewSolution: entry.Solution})
+	}
+	for _, entry := range diff.Removed {
+		changes = append(changes, database.RevisionChange{Kind: database.ChangeRemoved, DecreeNumber: entry.Number.String(), Order: decree.CanonicalOrder(entry.Order), OldSolution: entry.Solution})
+	}
+	for _, change := range diff.Changed {
+		changes = append(changes, database.RevisionChange{Kind: database.ChangeChanged, DecreeNumber: change.New.Number.String(), Order: decree.CanonicalOrder(change.New.Order), OldSolution: change.Old.Solution, NewSolution: change.New.Solution})
+	}
+	return changes
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
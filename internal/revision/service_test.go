@@ -0,0 +1,126 @@
+package revision
+
+import (
+	"testing"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeProcessor serves a document that the test replaces between tracks
+type fakeProcessor struct {
+	decree.Processor
+	doc *decree.Document
+}
+
+func (p *fakeProcessor) Document(year int) (*decree.Document, error) {
+	return p.doc, nil
+}
+
+func newTestService(t *testing.T) (*service, *fakeProcessor) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&database.Revision{}, &database.RevisionChange{}, &database.RevisionCursor{}); err != nil {
+		t.Fatal(err)
+	}
+	processor := &fakeProcessor{}
+	return NewService(processor, database.NewRevisionService(db)).(*service), processor
+}
+
+func document(revision string, solutions map[int]string) *decree.Document {
+	doc := &decree.Document{Year: 2023, Revision: revision, Entries: make(map[dossier.Number]decree.Entry)}
+	for value, solution := range solutions {
+		number := dossier.Number{Value: value, Year: 2023}
+		doc.Entries[number] = decree.Entry{Number: number, Solution: solution}
+	}
+	return doc
+}
+
+func TestChangesSinceIncludesRevisionsTrackedByOthers(t *testing.T) {
+	s, processor := newTestService(t)
+
+	processor.doc = document("a", map[int]string{1: "", 2: ""})
+	changes, err := s.ChangesSince("checker", 2023)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changes.Changed || changes.Complete {
+		t.Fatalf("first check: Changed=%v Complete=%v, want a full lookup", changes.Changed, changes.Complete)
+	}
+	if err := s.Advance("checker", changes); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another consumer, e.g. the feed, records the next revisions first
+	processor.doc = document("b", map[int]string{1: "12/P/2024", 2: ""})
+	if _, err := s.Track(2023); err != nil {
+		t.Fatal(err)
+	}
+	processor.doc = document("c", map[int]string{1: "12/P/2024", 2: "respins"})
+	if _, err := s.Track(2023); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err = s.ChangesSince("checker", 2023)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changes.Changed || !changes.Complete {
+		t.Fatalf("Changed=%v Complete=%v, want complete changes", changes.Changed, changes.Complete)
+	}
+	want := map[string]decree.FindState{"1/RD/2023": decree.StateFoundAndResolved, "2/RD/2023": decree.StateRejected}
+	if len(changes.States) != len(want) {
+		t.Fatalf("States = %v, want %v", changes.States, want)
+	}
+	for number, state := range want {
+		if changes.States[number] != state {
+			t.Errorf("state of %s = %v, want %v", number, changes.States[number], state)
+		}
+	}
+	if err := s.Advance("checker", changes); err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err = s.ChangesSince("checker", 2023)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes.Changed {
+		t.Errorf("Changed after advancing, want the revision to be processed")
+	}
+}
+
+func TestOrderFirstSeenMatchesCanonicalOrder(t *testing.T) {
+	s, processor := newTestService(t)
+
+	processor.doc = document("a", map[int]string{1: ""})
+	if _, err := s.Track(2023); err != nil {
+		t.Fatal(err)
+	}
+
+	// The row prints the order with leading zeros
+	number := dossier.Number{Value: 1, Year: 2023}
+	processor.doc = document("b", nil)
+	processor.doc.Entries[number] = decree.Entry{Number: number, Solution: "0123/P/2024", Order: "0123/P/2024"}
+	if _, err := s.Track(2023); err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := decree.ParseOrderNumber("123/p/2024")
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstSeen, err := s.revisionService.GetOrderFirstSeen(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if firstSeen == nil {
+		t.Errorf("GetOrderFirstSeen(%s) found nothing", order)
+	}
+}
@@ -0,0 +1,153 @@
+package subscription_checker
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/andiq123/cetatenie-analyzer/internal/oath"
+)
+
+// oathWaitMonths is how long a resolved dossier may wait for its oath
+// appointment before its subscription is removed
+const oathWaitMonths = 6
+
+// loadSchedule reads the oath scheduling lists when a subscription waits for them
+func (s *service) loadSchedule(subscriptions []database.Subscription) *oath.Schedule {
+	waiting := false
+	for _, sub := range subscriptions {
+		if sub.LastState != nil {
+			state := decree.FindState(*sub.LastState)
+			waiting = waiting || state == decree.StateAwaitingOath || state == decree.StateOathScheduled
+		}
+	}
+	if !waiting || !s.oath.Enabled() {
+		return nil
+	}
+
+	schedule, err := s.oath.Appointments()
+	if err != nil {
+		fmt.Printf("Error reading oath schedules: %v\n", err)
+		return nil
+	}
+	if !schedule.Complete() {
+		fmt.Printf("Could not read %d oath schedules, no subscription is removed in this check\n", len(schedule.Failed))
+	}
+	return schedule
+}
+
+// processOathSubscription follows a resolved dossier through the oath
+// scheduling lists: the chat is notified once its appointment is published
+// and again if it is moved, and the subscription is removed after the
+// ceremony day. Subscriptions are also removed once the lists are no longer
+// configured, or when no appointment was published within oathWaitMonths.
+func (s *service) processOathSubscription(ctx context.Context, sub database.Subscription, state decree.FindState, schedule *oath.Schedule) error {
+	if !s.oath.Enabled() {
+		return s.stopOathTracking(ctx, sub, "Listele de programare la depunerea jurământului nu mai sunt urmărite.")
+	}
+	// The state of a dossier awaiting its oath is stored once, when it is resolved
+	if state == decree.StateAwaitingOath && sub.LastCheckedAt != nil && time.Now().After(sub.LastCheckedAt.AddDate(0, oathWaitMonths, 0)) {
+		return s.stopOathTracking(ctx, sub, fmt.Sprintf("Dosarul nu a apărut în listele de programare la depunerea jurământului în ultimele %d luni.", oathWaitMonths))
+	}
+	if schedule == nil {
+		return nil
+	}
+	match, err := dossier.Parse(sub.DecreeNumber)
+	if err != nil {
+		return fmt.Errorf(errorCheckingDecree, err)
+	}
+	appointment, scheduled := schedule.Appointments[match.Number]
+
+	if state == decree.StateOathScheduled {
+		ceremony := sub.OathDate
+		if scheduled {
+			ceremony = &appointment.Date
+		}
+		// A list that failed to load may still hold the appointment, and the
+		// lists are taken down after the ceremonies: only a past ceremony
+		// date read from complete lists ends the subscription
+		if ceremony != nil && schedule.Complete() && time.Now().After(ceremony.AddDate(0, 0, 1)) {
+			if err := s.subscriptionService.DeleteSubscription(sub.ChatID, sub.DecreeNumber); err != nil {
+				return fmt.Errorf(errorRemovingSubscription, err)
+			}
+			fmt.Printf("Successfully removed subscription for decree %s after its oath ceremony\n", sub.DecreeNumber)
+			return nil
+		}
+		if !scheduled || sub.OathDate != nil && sub.OathDate.Equal(appointment.Date) {
+			return nil
+		}
+		if sub.OathDate == nil {
+			// Scheduled before the date was stored, the chat knows this appointment
+			return s.subscriptionService.SetOathAppointment(sub.ChatID, sub.DecreeNumber, int(decree.StateOathScheduled), appointment.Date)
+		}
+	}
+	if !scheduled {
+		return nil
+	}
+
+	// Muted chats are told about the appointment once they turn notifications back on
+	profile, err := s.profileService.GetProfile(sub.ChatID)
+	if err != nil {
+		return fmt.Errorf(errorGettingProfile, err)
+	}
+	if !profile.NotificationsEnabled {
+		return nil
+	}
+
+	rescheduled := state == decree.StateOathScheduled
+	if err := s.notifier.Notify(ctx, sub.ChatID, formatAppointment(sub, appointment, rescheduled)); err != nil {
+		return fmt.Errorf(errorSendingMessage, err)
+	}
+	fmt.Printf("Successfully sent oath appointment to chat %d for decree %s\n", sub.ChatID, sub.DecreeNumber)
+
+	if err := s.subscriptionService.SetOathAppointment(sub.ChatID, sub.DecreeNumber, int(decree.StateOathScheduled), appointment.Date); err != nil {
+		return fmt.Errorf("error saving state of subscription: %w", err)
+	}
+	return nil
+}
+
+// stopOathTracking tells the chat why its dossier is no longer followed
+// through the oath lists and removes the subscription. Muted chats are not
+// told, the subscription is removed all the same.
+func (s *service) stopOathTracking(ctx context.Context, sub database.Subscription, reason string) error {
+	profile, err := s.profileService.GetProfile(sub.ChatID)
+	if err != nil {
+		return fmt.Errorf(errorGettingProfile, err)
+	}
+	if profile.NotificationsEnabled {
+		message := fmt.Sprintf("🕊 <b>Notificare</b>\n\nAm încetat urmărirea programării la jurământ pentru dosarul %s.%s\n\n%s Verifică programarea direct la autoritățile competente.\n\nAcest abonament a fost șters.",
+			sub.Title(), describeNote(sub), reason)
+		if err := s.notifier.Notify(ctx, sub.ChatID, message); err != nil {
+			return fmt.Errorf(errorSendingMessage, err)
+		}
+	}
+
+	if err := s.subscriptionService.DeleteSubscription(sub.ChatID, sub.DecreeNumber); err != nil {
+		return fmt.Errorf(errorRemovingSubscription, err)
+	}
+	fmt.Printf("Successfully removed subscription for decree %s, its oath is no longer tracked\n", sub.DecreeNumber)
+	return nil
+}
+
+func formatAppointment(sub database.Subscription, appointment oath.Appointment, rescheduled bool) string {
+	title := "🏛 <b>Programare la jurământ</b>\n\nDosarul %s a fost programat pentru depunerea jurământului.%s"
+	if rescheduled {
+		title = "🔁 <b>Programare la jurământ modificată</b>\n\nProgramarea dosarului %s pentru depunerea jurământului a fost mutată.%s"
+	}
+
+	date := appointment.Date.Format("02.01.2006")
+	if appointment.HasTime {
+		date += ", ora " + appointment.Date.Format("15:04")
+	}
+	location := "nespecificată, verifică documentul"
+	if appointment.Location != "" {
+		location = html.EscapeString(appointment.Location)
+	}
+
+	return fmt.Sprintf(title+"\n\n📅 Data: <b>%s</b>\n📍 Locația: <b>%s</b>\n📄 Sursa: %s\n\nAbonamentul va fi șters automat după ceremonie.",
+		sub.Title(), describeNote(sub), date, location, html.EscapeString(appointment.Source))
+}
@@ -0,0 +1,191 @@
+package subscription_checker
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/andiq123/cetatenie-analyzer/internal/oath"
+)
+
+type fakeSubscriptions struct {
+	database.SubscriptionService
+	deleted   []string
+	oathDate  *time.Time
+	lastState *int
+}
+
+func (f *fakeSubscriptions) DeleteSubscription(chatID int64, decreeNumber string) error {
+	f.deleted = append(f.deleted, decreeNumber)
+	return nil
+}
+
+func (f *fakeSubscriptions) SetOathAppointment(chatID int64, decreeNumber string, state int, date time.Time) error {
+	f.oathDate = &date
+	return nil
+}
+
+type fakeProfiles struct {
+	database.ProfileService
+}
+
+func (fakeProfiles) GetProfile(chatID int64) (*database.Profile, error) {
+	return &database.Profile{ChatID: chatID, NotificationsEnabled: true}, nil
+}
+
+type fakeNotifier struct {
+	sent []string
+	err  error
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, chatID int64, text string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, text)
+	return nil
+}
+
+func (f *fakeNotifier) DeliverDue(ctx context.Context) error {
+	return nil
+}
+
+type fakeOath struct {
+	oath.Service
+	disabled bool
+}
+
+func (f fakeOath) Enabled() bool {
+	return !f.disabled
+}
+
+func TestProcessOathSubscription(t *testing.T) {
+	number := dossier.Number{Value: 123, Year: 2023}
+	past := time.Now().AddDate(0, 0, -3)
+	longAgo := time.Now().AddDate(0, -oathWaitMonths, -1)
+	future := time.Now().AddDate(0, 0, 10)
+	moved := future.AddDate(0, 0, 7)
+
+	tests := []struct {
+		name         string
+		state        decree.FindState
+		oathDate     *time.Time
+		checkedAt    *time.Time
+		disabled     bool
+		appointments map[dossier.Number]oath.Appointment
+		failed       []string
+		wantDeleted  bool
+		wantSent     string
+		wantOathDate *time.Time
+	}{
+		{
+			name:         "awaiting and published",
+			state:        decree.StateAwaitingOath,
+			appointments: map[dossier.Number]oath.Appointment{number: {Number: number, Date: future}},
+			wantSent:     "Programare la jurământ",
+			wantOathDate: &future,
+		},
+		{
+			name:      "awaiting and not published yet",
+			state:     decree.StateAwaitingOath,
+			checkedAt: &past,
+		},
+		{
+			name:        "awaiting too long",
+			state:       decree.StateAwaitingOath,
+			checkedAt:   &longAgo,
+			wantDeleted: true,
+			wantSent:    "ultimele 6 luni",
+		},
+		{
+			name:         "published after a long wait",
+			state:        decree.StateOathScheduled,
+			oathDate:     &future,
+			checkedAt:    &longAgo,
+			appointments: map[dossier.Number]oath.Appointment{number: {Number: number, Date: future}},
+		},
+		{
+			name:        "oath lists removed",
+			state:       decree.StateOathScheduled,
+			oathDate:    &future,
+			disabled:    true,
+			wantDeleted: true,
+			wantSent:    "nu mai sunt urmărite",
+		},
+		{
+			name:         "scheduled and unchanged",
+			state:        decree.StateOathScheduled,
+			oathDate:     &future,
+			appointments: map[dossier.Number]oath.Appointment{number: {Number: number, Date: future}},
+		},
+		{
+			name:         "rescheduled",
+			state:        decree.StateOathScheduled,
+			oathDate:     &future,
+			appointments: map[dossier.Number]oath.Appointment{number: {Number: number, Date: moved}},
+			wantSent:     "modificată",
+			wantOathDate: &moved,
+		},
+		{
+			name:     "list missing before the ceremony",
+			state:    decree.StateOathScheduled,
+			oathDate: &future,
+		},
+		{
+			name:     "list failed after the ceremony",
+			state:    decree.StateOathScheduled,
+			oathDate: &past,
+			failed:   []string{"https://example.org/lista"},
+		},
+		{
+			name:        "ceremony over",
+			state:       decree.StateOathScheduled,
+			oathDate:    &past,
+			wantDeleted: true,
+		},
+		{
+			name:         "ceremony over and still listed",
+			state:        decree.StateOathScheduled,
+			oathDate:     &past,
+			appointments: map[dossier.Number]oath.Appointment{number: {Number: number, Date: past}},
+			wantDeleted:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscriptions := &fakeSubscriptions{}
+			notifier := &fakeNotifier{}
+			s := &service{subscriptionService: subscriptions, profileService: fakeProfiles{}, notifier: notifier, oath: fakeOath{disabled: tt.disabled}}
+
+			state := int(tt.state)
+			sub := database.Subscription{ChatID: 1, DecreeNumber: number.String(), LastState: &state, LastCheckedAt: tt.checkedAt, OathDate: tt.oathDate}
+			appointments := tt.appointments
+			if appointments == nil {
+				appointments = make(map[dossier.Number]oath.Appointment)
+			}
+			schedule := &oath.Schedule{Appointments: appointments, Failed: tt.failed}
+
+			if err := s.processOathSubscription(context.Background(), sub, tt.state, schedule); err != nil {
+				t.Fatal(err)
+			}
+
+			if deleted := len(subscriptions.deleted) > 0; deleted != tt.wantDeleted {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+			switch {
+			case tt.wantSent == "" && len(notifier.sent) > 0:
+				t.Errorf("sent %q, want no notification", notifier.sent)
+			case tt.wantSent != "" && (len(notifier.sent) != 1 || !strings.Contains(notifier.sent[0], tt.wantSent)):
+				t.Errorf("sent %q, want one notification containing %q", notifier.sent, tt.wantSent)
+			}
+			if tt.wantOathDate != nil && (subscriptions.oathDate == nil || !subscriptions.oathDate.Equal(*tt.wantOathDate)) {
+				t.Errorf("stored oath date %v, want %v", subscriptions.oathDate, *tt.wantOathDate)
+			}
+		})
+	}
+}
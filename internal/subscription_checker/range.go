@@ -0,0 +1,76 @@
+package subscription_checker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+)
+
+// maxListedNumbers limits the resolved numbers listed in a single range notification
+const maxListedNumbers = 100
+
+// processRangeSubscription notifies the numbers of a range or year
+// subscription resolved since the previous check, in a single message.
+// The first check only records what is already resolved.
+func (s *service) processRangeSubscription(ctx context.Context, sub database.Subscription) error {
+	// Muted chats are told about everything resolved meanwhile once they turn notifications back on
+	profile, err := s.profileService.GetProfile(sub.ChatID)
+	if err != nil {
+		return fmt.Errorf(errorGettingProfile, err)
+	}
+	if !profile.NotificationsEnabled {
+		return nil
+	}
+
+	doc, err := s.decreeService.Document(sub.RangeYear)
+	if err != nil {
+		return fmt.Errorf(errorCheckingDecree, err)
+	}
+
+	resolved := doc.Resolved(sub.RangeFrom, sub.RangeTo)
+	snapshot := make([]byte, (sub.RangeTo-sub.RangeFrom)/8+1)
+	var newlyResolved []int
+	for _, number := range resolved {
+		bit := number - sub.RangeFrom
+		if sub.ResolvedSnapshot != nil && !hasBit(sub.ResolvedSnapshot, bit) {
+			newlyResolved = append(newlyResolved, number)
+		}
+		setBit(snapshot, bit)
+	}
+
+	if len(newlyResolved) > 0 {
+		if err := s.notifier.Notify(ctx, sub.ChatID, formatRangeNotification(sub, newlyResolved)); err != nil {
+			return fmt.Errorf(errorSendingMessage, err)
+		}
+		fmt.Printf("Successfully sent notification to chat %d for %d dossiers in %s\n", sub.ChatID, len(newlyResolved), sub.DecreeNumber)
+	}
+
+	if err := s.subscriptionService.SetResolvedSnapshot(sub.ID, snapshot); err != nil {
+		return fmt.Errorf("error saving resolved snapshot: %w", err)
+	}
+	return nil
+}
+
+func formatRangeNotification(sub database.Subscription, numbers []int) string {
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("🎉 <b>Notificare</b>\n\n<b>%d dosare</b> din %s au fost rezolvate:\n\n", len(numbers), sub.Title()))
+	for i, number := range numbers {
+		if i == maxListedNumbers {
+			message.WriteString(fmt.Sprintf("\n… și încă %d", len(numbers)-maxListedNumbers))
+			break
+		}
+		message.WriteString(fmt.Sprintf("• <code>%d/RD/%d</code>\n", number, sub.RangeYear))
+	}
+	message.WriteString(describeNote(sub))
+	return message.String()
+}
+
+func hasBit(bits []byte, i int) bool {
+	return i/8 < len(bits) && bits[i/8]&(1<<(i%8)) != 0
+}
+
+func setBit(bits []byte, i int) {
+	bits[i/8] |= 1 << (i % 8)
+}
@@ -2,45 +2,74 @@ package subscription_checker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"html"
+	"sync"
 	"time"
 
 	"github.com/andiq123/cetatenie-analyzer/internal/database"
 	"github.com/andiq123/cetatenie-analyzer/internal/decree"
-	"github.com/andiq123/cetatenie-analyzer/internal/telegram_bot"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/andiq123/cetatenie-analyzer/internal/notification"
+	"github.com/andiq123/cetatenie-analyzer/internal/oath"
+	"github.com/andiq123/cetatenie-analyzer/internal/revision"
 )
 
 const (
 	operationTimeout          = 30 * time.Second
 	errorGettingSubscriptions = "error getting subscriptions: %w"
+	errorGettingProfile       = "error getting profile: %w"
 	errorCheckingDecree       = "error checking decree: %w"
 	errorSendingMessage       = "error sending message: %w"
 	errorRemovingSubscription = "error removing subscription: %w"
 )
 
+// ErrCheckRunning is returned when a check starts while another one runs.
+// Both would read the same revision cursors and notify the same chats twice.
+var ErrCheckRunning = errors.New("subscription check already running")
+
 // Service defines the interface for subscription checking functionality
 type Service interface {
 	CheckAllSubscriptions() error
+	// Running reports whether a check is in progress
+	Running() bool
 }
 
 // service implements the Service interface
 type service struct {
 	subscriptionService database.SubscriptionService
+	profileService      database.ProfileService
 	decreeService       decree.Processor
-	bot                 telegram_bot.BotService
+	notifier            notification.Service
+	revisions           revision.Service
+	oath                oath.Service
+
+	// running is held for the duration of a check
+	running sync.Mutex
 }
 
 // NewService creates a new instance of the subscription checker service
-func NewService(subscriptionService database.SubscriptionService, decreeService decree.Processor, bot telegram_bot.BotService) Service {
+func NewService(subscriptionService database.SubscriptionService, profileService database.ProfileService, decreeService decree.Processor, notifier notification.Service, revisions revision.Service, oath oath.Service) Service {
 	return &service{
 		subscriptionService: subscriptionService,
+		profileService:      profileService,
 		decreeService:       decreeService,
-		bot:                 bot,
+		notifier:            notifier,
+		revisions:           revisions,
+		oath:                oath,
 	}
 }
 
-// CheckAllSubscriptions retrieves all subscriptions and checks their states
+// CheckAllSubscriptions retrieves all subscriptions and checks their states.
+// Dossiers whose state is already known are notified from the changes of a
+// new PDF revision instead of being looked up one by one.
 func (s *service) CheckAllSubscriptions() error {
+	if !s.running.TryLock() {
+		return ErrCheckRunning
+	}
+	defer s.running.Unlock()
+
 	ctx, cancel := context.WithTimeout(context.Background(), operationTimeout)
 	defer cancel()
 
@@ -55,50 +84,260 @@ func (s *service) CheckAllSubscriptions() error {
 
 	fmt.Printf("Found %d subscriptions to check\n", len(subscriptions))
 
+	run := checkRun{
+		revisions: s.trackRevisions(subscriptions),
+		schedule:  s.loadSchedule(subscriptions),
+	}
+	// Years with a failed subscription are not advanced: the next check
+	// applies their changes again, and retries what was not notified
+	failed := make(map[int]bool)
 	for _, sub := range subscriptions {
-		if err := s.processSubscription(ctx, sub); err != nil {
+		if err := s.processSubscription(ctx, sub, run); err != nil {
 			fmt.Printf("Error processing subscription %s: %v\n", sub.DecreeNumber, err)
+			if match, err := dossier.Parse(sub.DecreeNumber); err == nil {
+				failed[match.Number.Year] = true
+			}
 		}
 	}
+	s.advanceRevisions(run.revisions, failed)
 
 	return nil
 }
 
-func (s *service) processSubscription(ctx context.Context, sub database.Subscription) error {
+func (s *service) Running() bool {
+	if !s.running.TryLock() {
+		return true
+	}
+	s.running.Unlock()
+	return false
+}
+
+// checkRun holds what a check loads once for every subscription
+type checkRun struct {
+	revisions map[int]*revision.Changes
+	// schedule is nil when no subscription awaits its oath or no list could be read
+	schedule *oath.Schedule
+}
+
+// checkerConsumer names the checker's revision cursors
+const checkerConsumer = "subscription_checker"
+
+// trackRevisions collects what changed since the previous check in every
+// year with single dossier subscriptions. Years that fail to track are left
+// out, so their subscriptions are looked up one by one.
+func (s *service) trackRevisions(subscriptions []database.Subscription) map[int]*revision.Changes {
+	revisions := make(map[int]*revision.Changes)
+	for _, sub := range subscriptions {
+		if sub.IsRange() {
+			continue
+		}
+		match, err := dossier.Parse(sub.DecreeNumber)
+		if err != nil {
+			continue
+		}
+		year := match.Number.Year
+		if _, ok := revisions[year]; ok {
+			continue
+		}
+
+		changes, err := s.revisions.ChangesSince(checkerConsumer, year)
+		if err != nil {
+			fmt.Printf("Error tracking revision of %d: %v\n", year, err)
+			continue
+		}
+		revisions[year] = changes
+	}
+	return revisions
+}
+
+// advanceRevisions records that the check processed the tracked revisions
+// of every year but the failed ones
+func (s *service) advanceRevisions(revisions map[int]*revision.Changes, failed map[int]bool) {
+	for year, changes := range revisions {
+		if failed[year] {
+			fmt.Printf("Keeping the revisions of %d for the next check\n", year)
+			continue
+		}
+		if err := s.revisions.Advance(checkerConsumer, changes); err != nil {
+			fmt.Printf("Error saving checked revision of %d: %v\n", year, err)
+		}
+	}
+}
+
+func (s *service) processSubscription(ctx context.Context, sub database.Subscription, run checkRun) error {
+	if sub.IsRange() {
+		return s.processRangeSubscription(ctx, sub)
+	}
+	if sub.LastState != nil {
+		if state := decree.FindState(*sub.LastState); state == decree.StateAwaitingOath || state == decree.StateOathScheduled {
+			return s.processOathSubscription(ctx, sub, state, run.schedule)
+		}
+	}
+
+	match, err := dossier.Parse(sub.DecreeNumber)
+	if err != nil {
+		return fmt.Errorf(errorCheckingDecree, err)
+	}
+	tracked, ok := run.revisions[match.Number.Year]
+	if !ok || needsLookup(sub, tracked) {
+		return s.lookupSubscription(ctx, sub)
+	}
+	if !tracked.Changed {
+		return nil
+	}
+
+	state, changed := tracked.States[sub.DecreeNumber]
+	if !changed {
+		return nil
+	}
+	return s.applyState(ctx, sub, state)
+}
+
+// needsLookup reports whether a subscription cannot rely on the revision
+// diff: its state was never checked, its final outcome was not delivered yet
+// (the chat was muted) or the changes since the previous check are unknown
+func needsLookup(sub database.Subscription, changes *revision.Changes) bool {
+	if sub.LastState == nil || decree.FindState(*sub.LastState).Closed() {
+		return true
+	}
+	return changes.Changed && !changes.Complete
+}
+
+// lookupSubscription checks a subscription against the current PDF
+func (s *service) lookupSubscription(ctx context.Context, sub database.Subscription) error {
 	state, _, err := s.decreeService.Handle(sub.DecreeNumber)
 	if err != nil {
 		return fmt.Errorf(errorCheckingDecree, err)
 	}
+	return s.applyState(ctx, sub, state)
+}
 
+// applyState notifies the chat about the state found for a subscription and
+// stores it. The state is stored only once the chat was told, so a failed
+// notification is sent again by the next check. Muted chats keep their
+// subscriptions and are notified once they turn notifications back on.
+func (s *service) applyState(ctx context.Context, sub database.Subscription, state decree.FindState) error {
+	profile, err := s.profileService.GetProfile(sub.ChatID)
+	if err != nil {
+		return fmt.Errorf(errorGettingProfile, err)
+	}
+	if !profile.NotificationsEnabled {
+		return s.saveState(sub, state)
+	}
+
+	previous := decree.StateNotFound
+	if sub.LastState != nil {
+		previous = decree.FindState(*sub.LastState)
+	}
 	switch state {
-	case decree.StateNotFound:
-		return s.handleNotFoundState(ctx, sub)
 	case decree.StateFoundAndResolved:
+		// The subscription is removed or moves on to the oath
 		return s.handleResolvedState(ctx, sub)
+	case decree.StateRejected, decree.StateReturned:
+		return s.handleClosedState(ctx, sub, state)
+	case decree.StateNotFound:
+		if sub.LastState == nil || previous != decree.StateNotFound {
+			if err := s.handleNotFoundState(ctx, sub); err != nil {
+				return err
+			}
+		}
+	case decree.StateSuspended:
+		if previous != decree.StateSuspended {
+			if err := s.notify(ctx, sub, fmt.Sprintf("⏸ <b>Notificare</b>\n\nDosarul %s <b>a fost suspendat</b>.%s\n\nSoluționarea este oprită până la reluarea procedurii. Te anunț când se schimbă starea.", sub.Title(), describeNote(sub))); err != nil {
+				return err
+			}
+		}
+	case decree.StateFoundButNotResolved:
+		if sub.LastState != nil && previous == decree.StateSuspended {
+			if err := s.notify(ctx, sub, fmt.Sprintf("▶️ <b>Notificare</b>\n\nSuspendarea dosarului %s <b>a fost ridicată</b>, dosarul este din nou în procesare.%s", sub.Title(), describeNote(sub))); err != nil {
+				return err
+			}
+		}
+	}
+	return s.saveState(sub, state)
+}
+
+// saveState records the state found for a subscription
+func (s *service) saveState(sub database.Subscription, state decree.FindState) error {
+	if err := s.subscriptionService.SetLastState(sub.ChatID, sub.DecreeNumber, int(state)); err != nil {
+		return fmt.Errorf("error saving state of subscription: %w", err)
 	}
+	return nil
+}
 
+// notify sends a state change of a subscription to its chat
+func (s *service) notify(ctx context.Context, sub database.Subscription, message string) error {
+	if err := s.notifier.Notify(ctx, sub.ChatID, message); err != nil {
+		return fmt.Errorf(errorSendingMessage, err)
+	}
+	fmt.Printf("Successfully sent notification to chat %d for decree %s\n", sub.ChatID, sub.DecreeNumber)
+	return nil
+}
+
+// handleClosedState notifies a rejection or return and removes the
+// subscription, the dossier will not change anymore
+func (s *service) handleClosedState(ctx context.Context, sub database.Subscription, state decree.FindState) error {
+	outcome := "<b>a fost respins</b>"
+	advice := "Pentru motivele respingerii și căile de atac te rugăm să contactezi autoritățile competente."
+	if state == decree.StateReturned {
+		outcome = "<b>a fost restituit</b> solicitantului"
+		advice = "Te rugăm să contactezi autoritățile competente pentru detalii."
+	}
+
+	message := fmt.Sprintf("❌ <b>Notificare</b>\n\nDosarul %s %s.%s\n\n%s\n\nAcest abonament va fi șters automat.", sub.Title(), outcome, describeNote(sub), advice)
+	if err := s.notify(ctx, sub, message); err != nil {
+		return err
+	}
+
+	if err := s.subscriptionService.DeleteSubscription(sub.ChatID, sub.DecreeNumber); err != nil {
+		return fmt.Errorf(errorRemovingSubscription, err)
+	}
+	fmt.Printf("Successfully removed subscription for decree %s\n", sub.DecreeNumber)
 	return nil
 }
 
 func (s *service) handleNotFoundState(ctx context.Context, sub database.Subscription) error {
-	message := fmt.Sprintf("⚠️ <b>Notificare</b>\n\nDosarul <code>%s</code> <b>nu a fost găsit</b>.\n\nTe rugăm să verifici numărul și anul, sau să contactezi autoritățile competente.", sub.DecreeNumber)
-	if err := s.bot.SendMessage(ctx, sub.ChatID, message); err != nil {
+	message := fmt.Sprintf("⚠️ <b>Notificare</b>\n\nDosarul %s <b>nu a fost găsit</b>.%s\n\nTe rugăm să verifici numărul și anul, sau să contactezi autoritățile competente.", sub.Title(), describeNote(sub))
+	if err := s.notifier.Notify(ctx, sub.ChatID, message); err != nil {
 		return fmt.Errorf(errorSendingMessage, err)
 	}
 	fmt.Printf("Successfully sent notification to chat %d for decree %s\n", sub.ChatID, sub.DecreeNumber)
 	return nil
 }
 
+// handleResolvedState notifies a resolution. When oath scheduling lists are
+// configured the subscription then waits for the oath appointment, otherwise
+// it is removed.
 func (s *service) handleResolvedState(ctx context.Context, sub database.Subscription) error {
-	message := fmt.Sprintf("🎉 <b>Notificare</b>\n\nDosarul <code>%s</code> <b>a fost găsit și rezolvat</b>!\n\nAcest abonament va fi șters automat.", sub.DecreeNumber)
-	if err := s.bot.SendMessage(ctx, sub.ChatID, message); err != nil {
+	tracksOath := s.oath.Enabled()
+	next := "Acest abonament va fi șters automat."
+	if tracksOath {
+		next = "🕊 Urmăresc în continuare listele de programare la depunerea jurământului și te anunț când apare dosarul tău."
+	}
+
+	message := fmt.Sprintf("🎉 <b>Notificare</b>\n\nDosarul %s <b>a fost găsit și rezolvat</b>!%s\n\n%s", sub.Title(), describeNote(sub), next)
+	if err := s.notifier.Notify(ctx, sub.ChatID, message); err != nil {
 		return fmt.Errorf(errorSendingMessage, err)
 	}
 	fmt.Printf("Successfully sent notification to chat %d for decree %s\n", sub.ChatID, sub.DecreeNumber)
 
+	if tracksOath {
+		if err := s.subscriptionService.SetLastState(sub.ChatID, sub.DecreeNumber, int(decree.StateAwaitingOath)); err != nil {
+			return fmt.Errorf("error saving state of subscription: %w", err)
+		}
+		return nil
+	}
+
 	if err := s.subscriptionService.DeleteSubscription(sub.ChatID, sub.DecreeNumber); err != nil {
 		return fmt.Errorf(errorRemovingSubscription, err)
 	}
 	fmt.Printf("Successfully removed subscription for decree %s\n", sub.DecreeNumber)
 	return nil
 }
+
+func describeNote(sub database.Subscription) string {
+	if sub.Note == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n📝 <i>%s</i>", html.EscapeString(sub.Note))
+}
@@ -0,0 +1,103 @@
+package subscription_checker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+)
+
+// blockingSubscriptions holds the check that loads the subscriptions until release is closed
+type blockingSubscriptions struct {
+	database.SubscriptionService
+	loading chan struct{}
+	release chan struct{}
+}
+
+func (f *blockingSubscriptions) GetAllSubscriptions() ([]database.Subscription, error) {
+	f.loading <- struct{}{}
+	<-f.release
+	return nil, nil
+}
+
+func TestCheckAllSubscriptionsRunsOneCheckAtATime(t *testing.T) {
+	subscriptions := &blockingSubscriptions{loading: make(chan struct{}), release: make(chan struct{})}
+	s := &service{subscriptionService: subscriptions}
+
+	done := make(chan error)
+	go func() { done <- s.CheckAllSubscriptions() }()
+	<-subscriptions.loading
+
+	if !s.Running() {
+		t.Error("Running() = false during a check")
+	}
+	if err := s.CheckAllSubscriptions(); !errors.Is(err, ErrCheckRunning) {
+		t.Errorf("second check returned %v, want ErrCheckRunning", err)
+	}
+
+	close(subscriptions.release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if s.Running() {
+		t.Error("Running() = true after the check")
+	}
+
+	// The next check runs once the previous one finished
+	go func() { <-subscriptions.loading }()
+	if err := s.CheckAllSubscriptions(); err != nil {
+		t.Errorf("check after the previous one returned %v", err)
+	}
+}
+
+func (f *fakeSubscriptions) SetLastState(chatID int64, decreeNumber string, state int) error {
+	f.lastState = &state
+	return nil
+}
+
+func TestApplyStateSavesOnlyNotifiedStates(t *testing.T) {
+	suspended, pending, notFound := int(decree.StateSuspended), int(decree.StateFoundButNotResolved), int(decree.StateNotFound)
+
+	tests := []struct {
+		name      string
+		last      *int
+		state     decree.FindState
+		notifyErr error
+		wantSent  bool
+		wantSaved bool
+	}{
+		{name: "suspended", last: &pending, state: decree.StateSuspended, wantSent: true, wantSaved: true},
+		{name: "suspension not delivered", last: &pending, state: decree.StateSuspended, notifyErr: errors.New("timeout")},
+		{name: "suspension lifted", last: &suspended, state: decree.StateFoundButNotResolved, wantSent: true, wantSaved: true},
+		{name: "lifted suspension not delivered", last: &suspended, state: decree.StateFoundButNotResolved, notifyErr: errors.New("timeout")},
+		{name: "still suspended", last: &suspended, state: decree.StateSuspended, wantSaved: true},
+		{name: "first check", state: decree.StateFoundButNotResolved, wantSaved: true},
+		{name: "not found", last: &pending, state: decree.StateNotFound, wantSent: true, wantSaved: true},
+		{name: "still not found", last: &notFound, state: decree.StateNotFound, wantSaved: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscriptions := &fakeSubscriptions{}
+			notifier := &fakeNotifier{err: tt.notifyErr}
+			s := &service{subscriptionService: subscriptions, profileService: fakeProfiles{}, notifier: notifier}
+
+			sub := database.Subscription{ChatID: 1, DecreeNumber: "1/RD/2023", LastState: tt.last}
+			err := s.applyState(context.Background(), sub, tt.state)
+			if (err != nil) != (tt.notifyErr != nil) {
+				t.Errorf("applyState returned %v", err)
+			}
+			if sent := len(notifier.sent) > 0; sent != tt.wantSent {
+				t.Errorf("sent = %v, want %v", sent, tt.wantSent)
+			}
+			if saved := subscriptions.lastState != nil; saved != tt.wantSaved {
+				t.Fatalf("saved = %v, want %v", saved, tt.wantSaved)
+			}
+			if tt.wantSaved && decree.FindState(*subscriptions.lastState) != tt.state {
+				t.Errorf("saved state %v, want %v", decree.FindState(*subscriptions.lastState), tt.state)
+			}
+		})
+	}
+}
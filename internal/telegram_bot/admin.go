@@ -0,0 +1,293 @@
+package telegram_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/andiq123/cetatenie-analyzer/internal/subscription_checker"
+	"github.com/andiq123/cetatenie-analyzer/internal/timer"
+	"github.com/go-telegram/bot/models"
+)
+
+// Admin commands, registered only for the chats listed in ADMIN_CHAT_IDS
+const (
+	cmdAdminStats     = "admin_stats"
+	cmdAdminCheckNow  = "admin_check_now"
+	cmdAdminCache     = "admin_cache"
+	cmdAdminSources   = "admin_sources"
+	cmdAdminUser      = "admin_user"
+	cmdAdminRevisions = "admin_revisions"
+	cmdAdminArchive   = "admin_archive"
+	cmdAdminArchived  = "admin_archive_get"
+	cmdAdminAsOf      = "admin_asof"
+)
+
+const checkAlreadyRunning = "⏳ <b>Verificare deja în curs</b>\n\nAșteaptă finalizarea ei, apoi poți porni alta."
+
+var adminCommands = []models.BotCommand{
+	{Command: cmdAdminStats, Description: "📊 Statistici utilizatori, abonamente și căutări"},
+	{Command: cmdAdminCheckNow, Description: "🔄 Verifică acum toate abonamentele"},
+	{Command: cmdAdminCache, Description: "🗄 Vezi sau golește cache-ul (ex: /admin_cache clear)"},
+	{Command: cmdAdminSources, Description: "📄 Vezi sursele PDF și reviziile lor"},
+	{Command: cmdAdminUser, Description: "👤 Vezi datele unui chat (ex: /admin_user 123456)"},
+	{Command: cmdAdminRevisions, Description: "🆕 Vezi ce s-a schimbat între reviziile PDF (ex: /admin_revisions 12)"},
+	{Command: cmdAdminArchive, Description: "🗃 Vezi reviziile PDF arhivate (ex: /admin_archive 2024)"},
+	{Command: cmdAdminArchived, Description: "📥 Descarcă o revizie arhivată (ex: /admin_archive_get 2024 3fa9c1)"},
+	{Command: cmdAdminAsOf, Description: "🕰 Verifică un dosar la o dată trecută (ex: /admin_asof 123/RD/2024 03.05.2025)"},
+}
+
+// SubscriptionChecker triggers a check of every subscription
+type SubscriptionChecker interface {
+	CheckAllSubscriptions() error
+	Running() bool
+}
+
+// loadAdminChatIDs reads the comma separated ADMIN_CHAT_IDS environment variable
+func loadAdminChatIDs() map[int64]bool {
+	admins := make(map[int64]bool)
+	for _, field := range strings.Split(os.Getenv("ADMIN_CHAT_IDS"), ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		chatID, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			fmt.Printf("Ignoring invalid admin chat ID %q: %v\n", field, err)
+			continue
+		}
+		admins[chatID] = true
+	}
+	return admins
+}
+
+// registerAdminCommands wires the admin commands and shows them only in the admins' chats
+func (b *botService) registerAdminCommands() {
+	if len(b.admins) == 0 {
+		return
+	}
+
+	b.bh.HandleCommand(cmdAdminStats, b.adminOnly(b.adminStatsCommand))
+	b.bh.HandleCommand(cmdAdminCheckNow, b.adminOnly(b.adminCheckNowCommand))
+	b.bh.HandleCommand(cmdAdminCache, b.adminOnly(b.adminCacheCommand))
+	b.bh.HandleCommand(cmdAdminSources, b.adminOnly(b.adminSourcesCommand))
+	b.bh.HandleCommand(cmdAdminUser, b.adminOnly(b.adminUserCommand))
+	b.bh.HandleCommand(cmdAdminRevisions, b.adminOnly(b.adminRevisionsCommand))
+	b.bh.HandleCommand(cmdAdminArchive, b.adminOnly(b.adminArchiveCommand))
+	b.bh.HandleCommand(cmdAdminArchived, b.adminOnly(b.adminArchivedCommand))
+	b.bh.HandleCommand(cmdAdminAsOf, b.adminOnly(b.adminAsOfCommand))
+	b.bh.HandleCommand(cmdBroadcast, b.adminOnly(b.broadcastCommand))
+	b.bh.HandleCommand(cmdBroadcastCancel, b.adminOnly(b.broadcastCancelCommand))
+
+	commands := append(append([]models.BotCommand{}, adminCommands...), broadcastCommands...)
+	for chatID := range b.admins {
+		b.bh.SetChatCommands(chatID, commands)
+	}
+}
+
+// adminOnly ignores the command unless it comes from an admin chat
+func (b *botService) adminOnly(handler CommandHandler) CommandHandler {
+	return func(ctx context.Context, update *models.Update) {
+		if !b.admins[update.Message.Chat.ID] {
+			fmt.Printf("Ignoring admin command from non-admin chat %d\n", update.Message.Chat.ID)
+			return
+		}
+		handler(ctx, update)
+	}
+}
+
+func (b *botService) adminStatsCommand(ctx context.Context, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	subscriptions, err := b.subscriptionService.GetAllSubscriptions()
+	if err != nil {
+		b.sendAdminError(ctx, chatID, err)
+		return
+	}
+
+	users := make(map[int64]bool)
+	perYear := make(map[int]int)
+	ranges := 0
+	for _, sub := range subscriptions {
+		users[sub.ChatID] = true
+		if sub.IsRange() {
+			ranges++
+			continue
+		}
+		if match, err := dossier.Parse(sub.DecreeNumber); err == nil {
+			perYear[match.Number.Year]++
+		}
+	}
+
+	var response strings.Builder
+	response.WriteString("📊 <b>Statistici</b>\n\n")
+	if chats, err := b.chatService.CountActiveChats(); err == nil {
+		response.WriteString(fmt.Sprintf("💬 Chaturi active: <b>%d</b>\n", chats))
+	}
+	response.WriteString(fmt.Sprintf("👥 Utilizatori cu abonamente: <b>%d</b>\n", len(users)))
+	response.WriteString(fmt.Sprintf("🔔 Abonamente: <b>%d</b>\n", len(subscriptions)))
+	for _, year := range sortedKeys(perYear) {
+		response.WriteString(fmt.Sprintf("   • %d: %d\n", year, perYear[year]))
+	}
+	if ranges > 0 {
+		response.WriteString(fmt.Sprintf("   • intervale și ani întregi: %d\n", ranges))
+	}
+	if lookups, err := b.lookupService.CountLookupsOn(time.Now()); err == nil {
+		response.WriteString(fmt.Sprintf("🔍 Căutări azi: <b>%d</b>", lookups))
+	}
+
+	b.sendAdminMessage(ctx, chatID, response.String())
+}
+
+func (b *botService) adminCheckNowCommand(ctx context.Context, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	if b.checker == nil {
+		b.sendAdminMessage(ctx, chatID, "⚠️ <b>Verificarea abonamentelor nu este disponibilă</b>")
+		return
+	}
+
+	if b.checker.Running() {
+		b.sendAdminMessage(ctx, chatID, checkAlreadyRunning)
+		return
+	}
+
+	b.sendAdminMessage(ctx, chatID, "🔄 <b>Verificarea abonamentelor a pornit...</b>")
+
+	go func() {
+		checkTimer := timer.NewTimer()
+		checkTimer.Start()
+		err := b.checker.CheckAllSubscriptions()
+		checkTimer.Stop()
+
+		// Another check may have started since Running was asked
+		if errors.Is(err, subscription_checker.ErrCheckRunning) {
+			b.sendAdminMessage(ctx, chatID, checkAlreadyRunning)
+			return
+		}
+		if err != nil {
+			b.sendAdminError(ctx, chatID, err)
+			return
+		}
+		b.sendAdminMessage(ctx, chatID, fmt.Sprintf("✅ <b>Verificare finalizată</b> în %s", timer.FormatDuration(checkTimer.Duration())))
+	}()
+}
+
+func (b *botService) adminCacheCommand(ctx context.Context, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	parts := strings.Fields(update.Message.Text)
+	if len(parts) > 1 && parts[1] == "clear" {
+		if err := b.processor.CleanUpCache(); err != nil {
+			b.sendAdminError(ctx, chatID, err)
+			return
+		}
+		b.sendAdminMessage(ctx, chatID, "🗑 <b>Cache golit</b>\n\nFișierele vor fi descărcate din nou la următoarea căutare.")
+		return
+	}
+
+	var response strings.Builder
+	response.WriteString("🗄 <b>Cache fișiere</b>\n\n")
+	for _, source := range b.processor.Sources() {
+		var status string
+		switch {
+		case source.Cached:
+			status = fmt.Sprintf("✅ %s, descărcat %s", formatSize(source.Size), source.FetchedAt.Format("02.01.2006 15:04"))
+		case source.Hash == "":
+			status = "➖ nedescărcat"
+		default:
+			status = "⌛ expirat"
+		}
+		response.WriteString(fmt.Sprintf("• %d: %s\n", source.Year, status))
+	}
+	response.WriteString("\nFolosește <code>/admin_cache clear</code> pentru a goli cache-ul.")
+
+	b.sendAdminMessage(ctx, chatID, response.String())
+}
+
+func (b *botService) adminSourcesCommand(ctx context.Context, update *models.Update) {
+	var response strings.Builder
+	response.WriteString("📄 <b>Surse PDF</b>\n")
+	for _, source := range b.processor.Sources() {
+		response.WriteString(fmt.Sprintf("\n<b>%d</b>\n%s\n", source.Year, source.URL))
+		if source.Hash == "" {
+			response.WriteString("Revizie: <i>necunoscută (nedescărcat)</i>\n")
+			continue
+		}
+		response.WriteString(fmt.Sprintf("Revizie: <code>%s</code>\nDescărcat: %s\n", source.Hash[:12], source.FetchedAt.Format("02.01.2006 15:04")))
+	}
+
+	if oathSources := b.processor.OathSources(); len(oathSources) > 0 {
+		response.WriteString("\n🏛 <b>Liste de programare la jurământ</b>\n")
+		for _, source := range oathSources {
+			response.WriteString(fmt.Sprintf("\n%s\n", source.URL))
+			if source.Hash == "" {
+				response.WriteString("Revizie: <i>necunoscută (nedescărcat)</i>\n")
+				continue
+			}
+			response.WriteString(fmt.Sprintf("Revizie: <code>%s</code>\nDescărcat: %s\n", source.Hash[:12], source.FetchedAt.Format("02.01.2006 15:04")))
+		}
+	}
+
+	b.sendAdminMessage(ctx, update.Message.Chat.ID, response.String())
+}
+
+func (b *botService) adminUserCommand(ctx context.Context, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	parts := strings.Fields(update.Message.Text)
+	if len(parts) < 2 {
+		b.sendAdminMessage(ctx, chatID, "❌ <b>Format invalid</b>\n\nExemplu: <code>/admin_user 123456</code>")
+		return
+	}
+	targetID, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		b.sendAdminMessage(ctx, chatID, "❌ <b>ID de chat invalid</b>")
+		return
+	}
+
+	subscriptions, err := b.subscriptionService.GetSubscriptions(targetID)
+	if err != nil {
+		b.sendAdminError(ctx, chatID, err)
+		return
+	}
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("👤 <b>Chat</b> <code>%d</code>\n\n", targetID))
+	response.WriteString(fmt.Sprintf("🔔 Abonamente: <b>%d</b>\n", len(subscriptions)))
+	for _, subscription := range subscriptions {
+		response.WriteString("• " + formatSubscription(subscription) + "\n")
+	}
+
+	b.sendAdminMessage(ctx, chatID, response.String())
+}
+
+func (b *botService) sendAdminMessage(ctx context.Context, chatID int64, text string) {
+	if err := b.bh.SendMessage(ctx, chatID, text); err != nil {
+		fmt.Printf("Error sending admin message: %v\n", err)
+	}
+}
+
+func (b *botService) sendAdminError(ctx context.Context, chatID int64, err error) {
+	b.sendAdminMessage(ctx, chatID, fmt.Sprintf(errorMessage, html.EscapeString(err.Error())))
+}
+
+func formatSize(size int) string {
+	return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+}
+
+func sortedKeys(m map[int]int) []int {
+	keys := make([]int, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
+}
@@ -0,0 +1,169 @@
+package telegram_bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/go-telegram/bot/models"
+)
+
+// maxListedArchived is the number of archived revisions shown by /admin_archive
+const maxListedArchived = 20
+
+// adminArchiveCommand lists the archived PDF revisions, optionally of a single year
+func (b *botService) adminArchiveCommand(ctx context.Context, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	year := 0
+	parts := strings.Fields(update.Message.Text)
+	if len(parts) > 1 {
+		parsed, err := strconv.Atoi(parts[1])
+		if err != nil {
+			b.sendAdminMessage(ctx, chatID, "❌ <b>An invalid</b>\n\nExemplu: <code>/admin_archive 2024</code>")
+			return
+		}
+		year = parsed
+	}
+
+	revisions, err := b.archive.List(year, maxListedArchived)
+	if err != nil {
+		b.sendAdminError(ctx, chatID, err)
+		return
+	}
+	if len(revisions) == 0 {
+		b.sendAdminMessage(ctx, chatID, "🗃 <b>Nicio revizie arhivată</b>\n\nReviziile sunt arhivate la fiecare descărcare a fișierelor PDF.")
+		return
+	}
+
+	var response strings.Builder
+	response.WriteString("🗃 <b>Revizii arhivate</b>\n\n")
+	for _, revision := range revisions {
+		response.WriteString(fmt.Sprintf("<b>%d</b> · <code>%s</code> · %s\n", revision.Year, revision.Hash[:12], formatSize(revision.Size)))
+		response.WriteString(fmt.Sprintf("   prima descărcare %s, văzută ultima dată %s\n",
+			revision.FetchedAt.Format("02.01.2006 15:04"), revision.LastSeenAt.Format("02.01.2006 15:04")))
+		if modified := archivedHeader(revision, "Last-Modified"); modified != "" {
+			response.WriteString(fmt.Sprintf("   Last-Modified: %s\n", html.EscapeString(modified)))
+		}
+	}
+	response.WriteString("\nFolosește <code>/admin_archive_get AN HASH</code> pentru a descărca o revizie.")
+
+	b.sendAdminMessage(ctx, chatID, response.String())
+}
+
+// adminArchivedCommand sends an archived revision as a PDF document
+func (b *botService) adminArchivedCommand(ctx context.Context, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	parts := strings.Fields(update.Message.Text)
+	if len(parts) < 3 {
+		b.sendAdminMessage(ctx, chatID, "❌ <b>Format invalid</b>\n\nExemplu: <code>/admin_archive_get 2024 3fa9c1</code>")
+		return
+	}
+	year, err := strconv.Atoi(parts[1])
+	if err != nil {
+		b.sendAdminMessage(ctx, chatID, "❌ <b>An invalid</b>\n\nExemplu: <code>/admin_archive_get 2024 3fa9c1</code>")
+		return
+	}
+
+	revision, data, err := b.archive.Find(year, strings.ToLower(parts[2]))
+	if err != nil {
+		b.sendArchiveError(ctx, chatID, err)
+		return
+	}
+
+	filename := fmt.Sprintf("art_11_anul_%d_%s.pdf", revision.Year, revision.Hash[:12])
+	caption := fmt.Sprintf("🗃 Revizia <code>%s</code> din %d\nPrima descărcare: %s", revision.Hash[:12], revision.Year, revision.FetchedAt.Format("02.01.2006 15:04"))
+	if err := b.bh.SendDocument(ctx, chatID, filename, data, caption); err != nil {
+		fmt.Printf("Error sending archived revision: %v\n", err)
+	}
+}
+
+// adminAsOfCommand repeats a lookup against the revision that was current at
+// the end of a past day, in the admin's timezone
+func (b *botService) adminAsOfCommand(ctx context.Context, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	usage := "❌ <b>Format invalid</b>\n\nExemplu: <code>/admin_asof 123/RD/2024 03.05.2025</code>"
+
+	parts := strings.Fields(update.Message.Text)
+	if len(parts) < 3 {
+		b.sendAdminMessage(ctx, chatID, usage)
+		return
+	}
+	match, err := dossier.Parse(strings.Join(parts[1:len(parts)-1], " "))
+	if err != nil {
+		b.sendAdminMessage(ctx, chatID, usage)
+		return
+	}
+
+	location := time.Local
+	if profile, err := b.profileService.GetProfile(chatID); err == nil {
+		location = profile.Location()
+	}
+	day, err := time.ParseInLocation("02.01.2006", parts[len(parts)-1], location)
+	if err != nil {
+		b.sendAdminMessage(ctx, chatID, usage)
+		return
+	}
+	endOfDay := day.AddDate(0, 0, 1).Add(-time.Second)
+
+	revision, data, err := b.archive.At(match.Number.Year, endOfDay)
+	if err != nil {
+		b.sendArchiveError(ctx, chatID, err)
+		return
+	}
+	doc, err := b.processor.ParseRevision(revision.Year, data)
+	if err != nil {
+		b.sendAdminError(ctx, chatID, err)
+		return
+	}
+
+	entry, found := doc.Lookup(match.Number)
+	state := decree.StateNotFound
+	if found {
+		state = entry.State()
+	}
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("🕰 <b>%s la %s</b>\n\n", match.Canonical(), day.Format("02.01.2006")))
+	response.WriteString(fmt.Sprintf("Stare: <b>%s</b>\n", stateLabel(state)))
+	if entry.Order != "" {
+		response.WriteString(fmt.Sprintf("Ordin: <b>%s</b>\n", html.EscapeString(entry.Order)))
+	}
+	if found {
+		response.WriteString(fmt.Sprintf("Rând: <i>%s</i>\n", html.EscapeString(entry.Solution)))
+	}
+	response.WriteString(fmt.Sprintf("\nRevizia <code>%s</code>, descărcată prima dată %s",
+		revision.Hash[:12], revision.FetchedAt.In(location).Format("02.01.2006 15:04")))
+
+	b.sendAdminMessage(ctx, chatID, response.String())
+}
+
+func (b *botService) sendArchiveError(ctx context.Context, chatID int64, err error) {
+	switch {
+	case errors.Is(err, database.ErrArchivedRevisionNotFound):
+		b.sendAdminMessage(ctx, chatID, "🗃 <b>Nicio revizie arhivată nu corespunde</b>")
+	case errors.Is(err, database.ErrAmbiguousRevision):
+		b.sendAdminMessage(ctx, chatID, "⚠️ <b>Mai multe revizii încep cu acest hash</b>\n\nScrie mai multe caractere din hash.")
+	default:
+		b.sendAdminError(ctx, chatID, err)
+	}
+}
+
+// archivedHeader returns an HTTP header recorded with an archived revision
+func archivedHeader(revision database.ArchivedRevision, name string) string {
+	var header http.Header
+	if err := json.Unmarshal([]byte(revision.Headers), &header); err != nil {
+		return ""
+	}
+	return header.Get(name)
+}
@@ -1,14 +1,17 @@
 package telegram_bot
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"html"
 	"log"
 	"os"
-	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 	"github.com/go-telegram/ui/keyboard/inline"
@@ -29,52 +32,118 @@ var botCommands = []models.BotCommand{
 	{Command: cmdStart, Description: "🎯 Pornește botul și vezi mesajul de bun venit"},
 	{Command: cmdHelp, Description: "❓ Vezi ajutor și informații despre comenzi"},
 	{Command: cmdMySubscriptions, Description: "📋 Vezi toate dosarele la care ești abonat"},
-	{Command: cmdAddSubscription, Description: "➕ Adaugă un dosar la notificări (ex: /adauga 123/RD/2023)"},
+	{Command: cmdAddSubscription, Description: "➕ Adaugă un dosar la notificări (ex: /adauga 123/RD/2023 Mama)"},
 	{Command: cmdRemoveSubscription, Description: "➖ Șterge un dosar din notificări (ex: /sterge 123/RD/2023)"},
 	{Command: cmdRemoveAllSubscriptions, Description: "🗑 Șterge toate abonamentele la dosare"},
+	{Command: cmdLabel, Description: "🏷 Pune o etichetă unui dosar (ex: /eticheta 123/RD/2023 Mama)"},
+	{Command: cmdNote, Description: "📝 Adaugă o notiță la un dosar (ex: /nota 123/RD/2023 text)"},
+	{Command: cmdExport, Description: "📤 Descarcă abonamentele ca fișier CSV"},
+	{Command: cmdReport, Description: "📑 Raport cu starea dosarelor (ex: /raport 123/RD/2023 456/RD/2022)"},
+	{Command: cmdHistory, Description: "🕘 Vezi ultimele tale căutări"},
+	{Command: cmdSearch, Description: "🔍 Caută un număr de dosar în toți anii (ex: /cauta 1234)"},
+	{Command: cmdOrder, Description: "📜 Vezi dosarele rezolvate printr-un ordin (ex: /ordin 1234/P/2024)"},
+	{Command: cmdEstimate, Description: "📈 Estimează când va fi rezolvat un dosar (ex: /estimare 123/RD/2023)"},
+	{Command: cmdStatistics, Description: "📊 Statistici pe an: dosare rezolvate, ultimele ordine (ex: /statistici 2024)"},
+	{Command: cmdSettings, Description: "⚙️ Vezi și modifică setările (notificări, fus orar)"},
+	{Command: cmdExportData, Description: "📦 Descarcă toate datele stocate despre tine"},
+	{Command: cmdEraseData, Description: "🧹 Șterge definitiv toate datele tale"},
 }
 
 // TelegramBot defines the interface for the Telegram bot functionality
 type TelegramBot interface {
-	Init(onMessage func(ctx context.Context, update *models.Update), ctx context.Context) error
+	Init(onMessage func(ctx context.Context, update *models.Update), onInlineQuery func(ctx context.Context, query *models.InlineQuery), ctx context.Context) error
 	SendMessage(ctx context.Context, chatID int64, text string) error
 	SendMessageWithSubscribe(ctx context.Context, chatID int64, text, decreeNumber string) error
+	SendMessageWithSubscribeAll(ctx context.Context, chatID int64, text string, decreeNumbers []string) error
+	AnswerInlineQuery(ctx context.Context, queryID string, results []models.InlineQueryResult, cacheTime int) error
+	SendMessageWithButtons(ctx context.Context, chatID int64, text string, buttons ...Button) error
+	SendMessageWithButtonRows(ctx context.Context, chatID int64, text string, rows [][]Button) error
+	SendEditableMessage(ctx context.Context, chatID int64, text string) (int, error)
+	SendDocument(ctx context.Context, chatID int64, filename string, data []byte, caption string) error
+	SendPhoto(ctx context.Context, chatID int64, filename string, data []byte, caption string) error
+	EditMessage(ctx context.Context, chatID int64, messageID int, text string) error
+	HandleCommand(cmd string, handler CommandHandler)
+	SetChatCommands(chatID int64, commands []models.BotCommand)
+	OnStart(hook func(ctx context.Context))
+}
+
+// CommandHandler handles a command registered through HandleCommand
+type CommandHandler func(ctx context.Context, update *models.Update)
+
+// Button is an inline keyboard button; OnSelect receives the chat it was tapped in
+type Button struct {
+	Text     string
+	OnSelect func(ctx context.Context, chatID int64)
 }
 
 // botHandler implements the TelegramBot interface
 type botHandler struct {
 	instance            *bot.Bot
+	username            string
 	subscriptionService database.SubscriptionService
+	chatService         database.ChatService
+	profileService      database.ProfileService
+	privacyService      database.PrivacyService
+	commands            map[string]CommandHandler
+	chatCommands        map[int64][]models.BotCommand
+	startHooks          []func(ctx context.Context)
 }
 
 // NewBotHandler creates a new instance of the Telegram bot handler
-func NewBotHandler(subscriptionService database.SubscriptionService) TelegramBot {
+func NewBotHandler(subscriptionService database.SubscriptionService, chatService database.ChatService, profileService database.ProfileService, privacyService database.PrivacyService) TelegramBot {
 	return &botHandler{
 		subscriptionService: subscriptionService,
+		chatService:         chatService,
+		profileService:      profileService,
+		privacyService:      privacyService,
+		commands:            make(map[string]CommandHandler),
+		chatCommands:        make(map[int64][]models.BotCommand),
 	}
 }
 
+// HandleCommand registers an additional command handler. It must be called before Init.
+func (h *botHandler) HandleCommand(cmd string, handler CommandHandler) {
+	h.commands[cmd] = handler
+}
+
+// OnStart registers a hook run in the background once the bot is ready to
+// send messages. It must be called before Init.
+func (h *botHandler) OnStart(hook func(ctx context.Context)) {
+	h.startHooks = append(h.startHooks, hook)
+}
+
+// SetChatCommands sets extra commands shown only in the given chat, on top of
+// the default ones. It must be called before Init.
+func (h *botHandler) SetChatCommands(chatID int64, commands []models.BotCommand) {
+	h.chatCommands[chatID] = commands
+}
+
 // Init initializes the bot with the provided token and sets up command handlers
-func (h *botHandler) Init(onMessage func(ctx context.Context, update *models.Update), ctx context.Context) error {
+func (h *botHandler) Init(onMessage func(ctx context.Context, update *models.Update), onInlineQuery func(ctx context.Context, query *models.InlineQuery), ctx context.Context) error {
 	token := os.Getenv("TELEGRAM_BOT_TOKEN")
 	if token == "" {
 		return fmt.Errorf("variabila de mediu TELEGRAM_BOT_TOKEN nu este setată")
 	}
 
+	webhook := loadWebhookConfig()
+
 	opts := []bot.Option{
+		bot.WithMiddlewares(h.trackChat, h.trackProfile),
 		bot.WithDefaultHandler(func(ctx context.Context, b *bot.Bot, update *models.Update) {
 			if update.Message == nil {
 				return
 			}
+			if update.Message.MigrateToChatID != 0 {
+				h.handleMigration(update.Message)
+				return
+			}
+			if !h.isAddressed(update.Message) {
+				return
+			}
 			onMessage(ctx, update)
 		}),
-		bot.WithMessageTextHandler("/"+cmdStart, bot.MatchTypeExact, h.startCommand),
-		bot.WithMessageTextHandler("/"+cmdHelp, bot.MatchTypeExact, h.helpCommand),
-		bot.WithMessageTextHandler("/"+cmdMySubscriptions, bot.MatchTypeExact, h.listSubscriptionsCommand),
-		bot.WithMessageTextHandler("/"+cmdRemoveAllSubscriptions, bot.MatchTypeExact, h.removeAllSubscriptionsCommand),
-		bot.WithMessageTextHandler("/"+cmdAddSubscription, bot.MatchTypePrefix, h.addSubscriptionCommand),
-		bot.WithMessageTextHandler("/"+cmdRemoveSubscription, bot.MatchTypePrefix, h.removeSubscriptionCommand),
 	}
+	opts = append(opts, webhook.botOptions()...)
 
 	var err error
 	h.instance, err = bot.New(token, opts...)
@@ -82,6 +151,41 @@ func (h *botHandler) Init(onMessage func(ctx context.Context, update *models.Upd
 		return fmt.Errorf("eroare la crearea botului: %w", err)
 	}
 
+	// The username is needed to recognize commands addressed as /comanda@BotName
+	me, err := h.instance.GetMe(ctx)
+	if err != nil {
+		return fmt.Errorf("eroare la obținerea informațiilor despre bot: %w", err)
+	}
+	h.username = me.Username
+
+	h.instance.RegisterHandlerMatchFunc(h.matchCommand(cmdStart), h.startCommand)
+	h.instance.RegisterHandlerMatchFunc(h.matchCommand(cmdHelp), h.helpCommand)
+	h.instance.RegisterHandlerMatchFunc(h.matchCommand(cmdMySubscriptions), h.listSubscriptionsCommand)
+	h.instance.RegisterHandlerMatchFunc(h.matchCommand(cmdRemoveAllSubscriptions), h.removeAllSubscriptionsCommand)
+	h.instance.RegisterHandlerMatchFunc(h.matchCommand(cmdAddSubscription), h.addSubscriptionCommand)
+	h.instance.RegisterHandlerMatchFunc(h.matchCommand(cmdRemoveSubscription), h.removeSubscriptionCommand)
+	h.instance.RegisterHandlerMatchFunc(h.matchCommand(cmdSettings), h.settingsCommand)
+	h.instance.RegisterHandlerMatchFunc(h.matchCommand(cmdLabel), h.labelCommand)
+	h.instance.RegisterHandlerMatchFunc(h.matchCommand(cmdNote), h.noteCommand)
+	h.instance.RegisterHandlerMatchFunc(h.matchCommand(cmdExportData), h.exportDataCommand)
+	h.instance.RegisterHandlerMatchFunc(h.matchCommand(cmdExport), h.exportCommand)
+	h.instance.RegisterHandlerMatchFunc(isDocumentUpload, h.importDocumentHandler)
+	h.instance.RegisterHandlerMatchFunc(h.matchCommand(cmdEraseData), h.eraseDataCommand)
+
+	for cmd, handler := range h.commands {
+		handler := handler
+		h.instance.RegisterHandlerMatchFunc(h.matchCommand(cmd), func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			handler(ctx, update)
+		})
+	}
+
+	// Inline mode must also be enabled for the bot through @BotFather
+	h.instance.RegisterHandlerMatchFunc(func(update *models.Update) bool {
+		return update.InlineQuery != nil
+	}, func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		onInlineQuery(ctx, update.InlineQuery)
+	})
+
 	// Set the bot commands with all parameters
 	_, err = h.instance.SetMyCommands(ctx, &bot.SetMyCommandsParams{
 		Commands:     botCommands,
@@ -92,6 +196,25 @@ func (h *botHandler) Init(onMessage func(ctx context.Context, update *models.Upd
 		return fmt.Errorf("eroare la setarea comenzilor botului: %w", err)
 	}
 
+	// Chat-specific commands (e.g. admin commands) stay hidden from the default scope
+	for chatID, commands := range h.chatCommands {
+		_, err = h.instance.SetMyCommands(ctx, &bot.SetMyCommandsParams{
+			Commands: append(append([]models.BotCommand{}, botCommands...), commands...),
+			Scope:    &models.BotCommandScopeChat{ChatID: chatID},
+		})
+		if err != nil {
+			log.Printf("Error setting commands for chat %d: %v", chatID, err)
+		}
+	}
+
+	for _, hook := range h.startHooks {
+		go hook(ctx)
+	}
+
+	if webhook.enabled() {
+		return h.startWebhook(ctx, webhook)
+	}
+
 	log.Println("🤖 Pornire bot Telegram...")
 	h.instance.Start(ctx)
 
@@ -110,6 +233,12 @@ func (h *botHandler) SendMessage(ctx context.Context, chatID int64, text string)
 
 // SendMessageWithSubscribe sends a message with a subscription button
 func (h *botHandler) SendMessageWithSubscribe(ctx context.Context, chatID int64, text, decreeNumber string) error {
+	// Button callbacks only receive the message, not the user who tapped, so
+	// taps cannot be checked against group admin rights: groups use the admin commands
+	if isGroupChatID(chatID) {
+		return h.SendMessage(ctx, chatID, text+fmt.Sprintf(groupSubscribeHint, decreeNumber))
+	}
+
 	kb := inline.New(h.instance).Row().Button("Adaugă la notificări", []byte(fmt.Sprintf("%s %s", cmdAddSubscription, decreeNumber)), h.onInlineKeyboardSelect)
 
 	_, err := h.instance.SendMessage(ctx, &bot.SendMessageParams{
@@ -121,6 +250,97 @@ func (h *botHandler) SendMessageWithSubscribe(ctx context.Context, chatID int64,
 	return err
 }
 
+// SendMessageWithSubscribeAll sends a message with a button subscribing to all given decrees
+func (h *botHandler) SendMessageWithSubscribeAll(ctx context.Context, chatID int64, text string, decreeNumbers []string) error {
+	if isGroupChatID(chatID) {
+		return h.SendMessage(ctx, chatID, text+fmt.Sprintf(groupSubscribeHint, decreeNumbers[0]))
+	}
+
+	label := fmt.Sprintf("🔔 Abonează-te la toate cele în așteptare (%d)", len(decreeNumbers))
+	kb := inline.New(h.instance, inline.NoDeleteAfterClick()).Row().Button(label, []byte(strings.Join(decreeNumbers, " ")), h.onSubscribeAllSelect)
+
+	_, err := h.instance.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb,
+	})
+	return err
+}
+
+// SendMessageWithButtons sends a message with a row of inline buttons
+func (h *botHandler) SendMessageWithButtons(ctx context.Context, chatID int64, text string, buttons ...Button) error {
+	return h.SendMessageWithButtonRows(ctx, chatID, text, [][]Button{buttons})
+}
+
+// SendMessageWithButtonRows sends a message with inline buttons laid out in rows
+func (h *botHandler) SendMessageWithButtonRows(ctx context.Context, chatID int64, text string, rows [][]Button) error {
+	kb := inline.New(h.instance)
+	for _, row := range rows {
+		kb.Row()
+		for _, button := range row {
+			onSelect := button.OnSelect
+			kb.Button(button.Text, nil, func(ctx context.Context, b *bot.Bot, mes models.MaybeInaccessibleMessage, data []byte) {
+				onSelect(ctx, mes.Message.Chat.ID)
+			})
+		}
+	}
+
+	_, err := h.instance.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        text,
+		ParseMode:   models.ParseModeHTML,
+		ReplyMarkup: kb,
+	})
+	return err
+}
+
+// SendEditableMessage sends a message and returns its ID so it can be edited later
+func (h *botHandler) SendEditableMessage(ctx context.Context, chatID int64, text string) (int, error) {
+	msg, err := h.instance.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:    chatID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return msg.ID, nil
+}
+
+// SendDocument sends a file to a chat
+func (h *botHandler) SendDocument(ctx context.Context, chatID int64, filename string, data []byte, caption string) error {
+	_, err := h.instance.SendDocument(ctx, &bot.SendDocumentParams{
+		ChatID:    chatID,
+		Document:  &models.InputFileUpload{Filename: filename, Data: bytes.NewReader(data)},
+		Caption:   caption,
+		ParseMode: models.ParseModeHTML,
+	})
+	return err
+}
+
+// SendPhoto sends an image to a chat, shown inline rather than as a file
+func (h *botHandler) SendPhoto(ctx context.Context, chatID int64, filename string, data []byte, caption string) error {
+	_, err := h.instance.SendPhoto(ctx, &bot.SendPhotoParams{
+		ChatID:    chatID,
+		Photo:     &models.InputFileUpload{Filename: filename, Data: bytes.NewReader(data)},
+		Caption:   caption,
+		ParseMode: models.ParseModeHTML,
+	})
+	return err
+}
+
+// EditMessage replaces the text of a previously sent message
+func (h *botHandler) EditMessage(ctx context.Context, chatID int64, messageID int, text string) error {
+	_, err := h.instance.EditMessageText(ctx, &bot.EditMessageTextParams{
+		ChatID:    chatID,
+		MessageID: messageID,
+		Text:      text,
+		ParseMode: models.ParseModeHTML,
+	})
+	return err
+}
+
 // Command handlers
 func (h *botHandler) listSubscriptionsCommand(ctx context.Context, b *bot.Bot, update *models.Update) {
 	subscriptions, err := h.subscriptionService.GetSubscriptions(update.Message.Chat.ID)
@@ -136,12 +356,21 @@ func (h *botHandler) listSubscriptionsCommand(ctx context.Context, b *bot.Bot, u
 	var response strings.Builder
 	response.WriteString("📋 <b>Abonamentele tale:</b>\n\n")
 	for _, subscription := range subscriptions {
-		response.WriteString(fmt.Sprintf("• Dosar: <code>%s</code>\n", subscription))
+		response.WriteString("• " + formatSubscription(subscription) + "\n")
+		if subscription.Note != "" {
+			response.WriteString(fmt.Sprintf("   📝 <i>%s</i>\n", html.EscapeString(subscription.Note)))
+		}
 	}
+	response.WriteString("\nFolosește /eticheta și /nota pentru a-ți organiza dosarele.")
 	h.SendMessage(ctx, update.Message.Chat.ID, response.String())
 }
 
 func (h *botHandler) addSubscriptionCommand(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if !h.canManageSubscriptions(ctx, update.Message) {
+		h.SendMessage(ctx, update.Message.Chat.ID, groupAdminOnly)
+		return
+	}
+
 	// Split the message into command and arguments
 	parts := strings.Fields(update.Message.Text)
 	if len(parts) < 2 {
@@ -149,18 +378,45 @@ func (h *botHandler) addSubscriptionCommand(ctx context.Context, b *bot.Bot, upd
 		return
 	}
 
-	// Get the decree number (everything after the command)
-	decreeNumber := strings.Join(parts[1:], " ")
-
-	// Validate the decree number format
-	if !regexp.MustCompile(decreePattern).MatchString(decreeNumber) {
+	// The dossier number may be followed by a label: /adauga 123/RD/2023 Mama
+	args := strings.Join(parts[1:], " ")
+	if r, label, isRange, ok := h.parseRangeArgument(ctx, update.Message.Chat.ID, args); isRange || !ok {
+		if isRange {
+			if utf8.RuneCountInString(label) > maxLabelLength {
+				h.SendMessage(ctx, update.Message.Chat.ID, fmt.Sprintf(labelTooLong, maxLabelLength))
+				return
+			}
+			h.addRangeSubscription(ctx, update.Message.Chat.ID, r, label)
+		}
+		return
+	}
+	match, label, err := dossier.ParseLeading(args)
+	if err != nil {
+		// Fall back to extracting the number from free text, e.g. "nr. 123/RD/2023 Mama";
+		// the text after the number is still the label
+		match, err = dossier.Parse(args)
+		if err == nil {
+			_, after, _ := strings.Cut(args, match.Raw)
+			label = strings.TrimSpace(after)
+		}
+	}
+	if err != nil {
 		h.SendMessage(ctx, update.Message.Chat.ID, "❌ <b>Format invalid</b>\n\nTe rog specifică numărul dosarului în formatul: <b>[număr]/RD/[an]</b>\nExemplu: <code>123/RD/2023</code>")
 		return
 	}
+	if utf8.RuneCountInString(label) > maxLabelLength {
+		h.SendMessage(ctx, update.Message.Chat.ID, fmt.Sprintf(labelTooLong, maxLabelLength))
+		return
+	}
+	decreeNumber := match.Canonical()
 
-	err := h.subscriptionService.CreateSubscription(update.Message.Chat.ID, decreeNumber)
+	err = h.subscriptionService.CreateSubscription(update.Message.Chat.ID, decreeNumber, label)
 	if err != nil {
 		if strings.Contains(err.Error(), "subscription already exists") {
+			if label != "" {
+				h.updateLabel(ctx, update.Message.Chat.ID, decreeNumber, label)
+				return
+			}
 			h.SendMessage(ctx, update.Message.Chat.ID, fmt.Sprintf("ℹ️ <b>Abonament existent</b>\n\nEști deja abonat la dosarul <code>%s</code>", decreeNumber))
 			return
 		}
@@ -168,10 +424,16 @@ func (h *botHandler) addSubscriptionCommand(ctx context.Context, b *bot.Bot, upd
 		return
 	}
 
-	h.SendMessage(ctx, update.Message.Chat.ID, fmt.Sprintf("✅ <b>Abonament adăugat</b>\n\nAi fost abonat cu succes la dosarul <code>%s</code>", decreeNumber))
+	subscription := database.Subscription{DecreeNumber: decreeNumber, Label: label}
+	h.SendMessage(ctx, update.Message.Chat.ID, formatCorrections(match)+fmt.Sprintf("✅ <b>Abonament adăugat</b>\n\nAi fost abonat cu succes la dosarul %s", formatSubscription(subscription)))
 }
 
 func (h *botHandler) removeSubscriptionCommand(ctx context.Context, b *bot.Bot, update *models.Update) {
+	if !h.canManageSubscriptions(ctx, update.Message) {
+		h.SendMessage(ctx, update.Message.Chat.ID, groupAdminOnly)
+		return
+	}
+
 	// Split the message into command and arguments
 	parts := strings.Fields(update.Message.Text)
 	if len(parts) < 2 {
@@ -179,27 +441,43 @@ func (h *botHandler) removeSubscriptionCommand(ctx context.Context, b *bot.Bot,
 		return
 	}
 
-	// Get the decree number (everything after the command)
-	decreeNumber := strings.Join(parts[1:], " ")
+	args := strings.Join(parts[1:], " ")
+	if r, _, isRange, ok := h.parseRangeArgument(ctx, update.Message.Chat.ID, args); isRange || !ok {
+		if isRange {
+			if err := h.subscriptionService.DeleteSubscription(update.Message.Chat.ID, r.String()); err != nil {
+				h.SendMessage(ctx, update.Message.Chat.ID, "❌ <b>Eroare la ștergerea abonamentului</b>\n\nTe rugăm să încerci din nou mai târziu.")
+				return
+			}
+			h.SendMessage(ctx, update.Message.Chat.ID, fmt.Sprintf("✅ <b>Abonament șters</b>\n\nAi fost dezabonat cu succes de la <code>%s</code>", r))
+		}
+		return
+	}
 
-	// Validate the decree number format
-	if !regexp.MustCompile(decreePattern).MatchString(decreeNumber) {
+	// Normalize the decree number (everything after the command)
+	match, err := dossier.Parse(args)
+	if err != nil {
 		h.SendMessage(ctx, update.Message.Chat.ID, "❌ <b>Format invalid</b>\n\nTe rog specifică numărul dosarului în formatul: <b>[număr]/RD/[an]</b>\nExemplu: <code>123/RD/2023</code>")
 		return
 	}
+	decreeNumber := match.Canonical()
 
 	if err := h.subscriptionService.DeleteSubscription(update.Message.Chat.ID, decreeNumber); err != nil {
 		h.SendMessage(ctx, update.Message.Chat.ID, "❌ <b>Eroare la ștergerea abonamentului</b>\n\nTe rugăm să încerci din nou mai târziu.")
 		return
 	}
 
-	h.SendMessage(ctx, update.Message.Chat.ID, fmt.Sprintf("✅ <b>Abonament șters</b>\n\nAi fost dezabonat cu succes de la dosarul <code>%s</code>", decreeNumber))
+	h.SendMessage(ctx, update.Message.Chat.ID, formatCorrections(match)+fmt.Sprintf("✅ <b>Abonament șters</b>\n\nAi fost dezabonat cu succes de la dosarul <code>%s</code>", decreeNumber))
 }
 
 func (h *botHandler) removeAllSubscriptionsCommand(ctx context.Context, b *bot.Bot, update *models.Update) {
 	// Add logging to debug the command
 	log.Printf("Received removeAllSubscriptionsCommand from chat ID: %d", update.Message.Chat.ID)
 
+	if !h.canManageSubscriptions(ctx, update.Message) {
+		h.SendMessage(ctx, update.Message.Chat.ID, groupAdminOnly)
+		return
+	}
+
 	if err := h.subscriptionService.DeleteAllSubscriptions(update.Message.Chat.ID); err != nil {
 		log.Printf("Error deleting all subscriptions: %v", err)
 		h.SendMessage(ctx, update.Message.Chat.ID, "❌ <b>Eroare la ștergerea abonamentelor</b>\n\nTe rugăm să încerci din nou mai târziu.")
@@ -218,15 +496,14 @@ func (h *botHandler) onInlineKeyboardSelect(ctx context.Context, b *bot.Bot, mes
 		return
 	}
 
-	decreeNumber := parts[1]
-
 	// Validate the decree number format
-	if !regexp.MustCompile(decreePattern).MatchString(decreeNumber) {
+	decreeNumber, err := dossier.Normalize(parts[1])
+	if err != nil {
 		h.SendMessage(ctx, mes.Message.Chat.ID, "❌ <b>Format invalid</b>\n\nTe rog specifică numărul dosarului în formatul: <b>[număr]/RD/[an]</b>\nExemplu: <code>123/RD/2023</code>")
 		return
 	}
 
-	err := h.subscriptionService.CreateSubscription(mes.Message.Chat.ID, decreeNumber)
+	err = h.subscriptionService.CreateSubscription(mes.Message.Chat.ID, decreeNumber, "")
 	if err != nil {
 		if strings.Contains(err.Error(), "subscription already exists") {
 			h.SendMessage(ctx, mes.Message.Chat.ID, fmt.Sprintf("ℹ️ <b>Abonament existent</b>\n\nEști deja abonat la dosarul <code>%s</code>", decreeNumber))
@@ -239,6 +516,29 @@ func (h *botHandler) onInlineKeyboardSelect(ctx context.Context, b *bot.Bot, mes
 	h.SendMessage(ctx, mes.Message.Chat.ID, fmt.Sprintf("✅ <b>Abonament adăugat</b>\n\nAi fost abonat cu succes la dosarul <code>%s</code>", decreeNumber))
 }
 
+func (h *botHandler) onSubscribeAllSelect(ctx context.Context, b *bot.Bot, mes models.MaybeInaccessibleMessage, data []byte) {
+	var added, existing, failed []string
+	for _, decreeNumber := range strings.Fields(string(data)) {
+		err := h.subscriptionService.CreateSubscription(mes.Message.Chat.ID, decreeNumber, "")
+		switch {
+		case err == nil:
+			added = append(added, decreeNumber)
+		case strings.Contains(err.Error(), "subscription already exists"):
+			existing = append(existing, decreeNumber)
+		default:
+			log.Printf("Error subscribing chat %d to %s: %v", mes.Message.Chat.ID, decreeNumber, err)
+			failed = append(failed, decreeNumber)
+		}
+	}
+
+	var response strings.Builder
+	response.WriteString("✅ <b>Abonamente actualizate</b>\n")
+	writeDecreeList(&response, "Adăugate", added)
+	writeDecreeList(&response, "Existente deja", existing)
+	writeDecreeList(&response, "Eșuate (încearcă din nou mai târziu)", failed)
+	h.SendMessage(ctx, mes.Message.Chat.ID, response.String())
+}
+
 func (h *botHandler) startCommand(ctx context.Context, b *bot.Bot, update *models.Update) {
 	h.sendWelcomeMessage(ctx, update.Message.Chat.ID)
 }
@@ -250,3 +550,22 @@ func (h *botHandler) helpCommand(ctx context.Context, b *bot.Bot, update *models
 func (h *botHandler) sendWelcomeMessage(ctx context.Context, chatID int64) {
 	h.SendMessage(ctx, chatID, startMessage)
 }
+
+// formatCorrections explains to the user how their input was normalized
+func formatCorrections(match dossier.Match) string {
+	if !match.Corrected() {
+		return ""
+	}
+	return fmt.Sprintf(correctionNotice, match.Raw, match.Canonical(), strings.Join(match.Corrections, ", "))
+}
+
+// writeDecreeList appends a titled list of decree numbers, skipping empty lists
+func writeDecreeList(sb *strings.Builder, title string, decreeNumbers []string) {
+	if len(decreeNumbers) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("\n<b>%s (%d):</b>\n", title, len(decreeNumbers)))
+	for _, decreeNumber := range decreeNumbers {
+		sb.WriteString(fmt.Sprintf("• <code>%s</code>\n", decreeNumber))
+	}
+}
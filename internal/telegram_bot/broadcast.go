@@ -0,0 +1,326 @@
+package telegram_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	cmdBroadcast       = "broadcast"
+	cmdBroadcastCancel = "broadcast_cancel"
+
+	// broadcastRate keeps broadcasts below Telegram's limit of 30 messages per second
+	broadcastRate = 25
+	// broadcastBatchSize is the number of chats loaded from the database at once
+	broadcastBatchSize = 100
+	// broadcastProgressEvery is how often (in chats) progress is reported to the admin
+	broadcastProgressEvery = 50
+)
+
+var broadcastCommands = []models.BotCommand{
+	{Command: cmdBroadcast, Description: "📣 Trimite un anunț tuturor utilizatorilor (ex: /broadcast text)"},
+	{Command: cmdBroadcastCancel, Description: "⏹ Oprește anunțurile în curs"},
+}
+
+// broadcastRuns tracks the broadcasts currently being sent by this process
+type broadcastRuns struct {
+	mu      sync.Mutex
+	cancels map[uint]context.CancelFunc
+}
+
+func (r *broadcastRuns) start(ctx context.Context, id uint) (context.Context, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, running := r.cancels[id]; running {
+		return nil, false
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	r.cancels[id] = cancel
+	return runCtx, true
+}
+
+func (r *broadcastRuns) finish(id uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if cancel, ok := r.cancels[id]; ok {
+		cancel()
+		delete(r.cancels, id)
+	}
+}
+
+func (r *broadcastRuns) cancelAll() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, cancel := range r.cancels {
+		cancel()
+	}
+	return len(r.cancels)
+}
+
+// broadcastCommand previews an announcement and asks for confirmation before sending it
+func (b *botService) broadcastCommand(ctx context.Context, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	// Keep the announcement's line breaks: everything after the command is the text
+	parts := strings.Fields(update.Message.Text)
+	text := ""
+	if len(parts) > 1 {
+		text = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(update.Message.Text), parts[0]))
+	}
+	if text == "" {
+		b.sendAdminMessage(ctx, chatID, "❌ <b>Format invalid</b>\n\nExemplu: <code>/broadcast Textul anunțului</code>\n\nTextul poate conține formatare HTML.")
+		return
+	}
+
+	total, err := b.chatService.CountActiveChats()
+	if err != nil {
+		b.sendAdminError(ctx, chatID, err)
+		return
+	}
+
+	broadcast, err := b.broadcastService.CreateBroadcast(chatID, text)
+	if err != nil {
+		b.sendAdminError(ctx, chatID, err)
+		return
+	}
+
+	preview := fmt.Sprintf("📣 <b>Previzualizare anunț #%d</b>\n\nVa fi trimis către <b>%d</b> chaturi:\n\n%s", broadcast.ID, total, text)
+	err = b.bh.SendMessageWithButtons(ctx, chatID, preview,
+		Button{Text: "✅ Trimite", OnSelect: func(ctx context.Context, chatID int64) {
+			b.confirmBroadcast(ctx, chatID, broadcast.ID)
+		}},
+		Button{Text: "❌ Anulează", OnSelect: func(ctx context.Context, chatID int64) {
+			b.discardBroadcast(ctx, chatID, broadcast.ID)
+		}},
+	)
+	if err != nil {
+		// Most likely the announcement is not valid HTML
+		b.sendAdminError(ctx, chatID, err)
+	}
+}
+
+func (b *botService) confirmBroadcast(ctx context.Context, chatID int64, id uint) {
+	broadcast, err := b.broadcastService.GetBroadcast(id)
+	if err != nil {
+		b.sendAdminError(ctx, chatID, err)
+		return
+	}
+	if broadcast.Status != database.BroadcastDraft {
+		b.sendAdminMessage(ctx, chatID, fmt.Sprintf("ℹ️ Anunțul #%d a fost deja procesat.", id))
+		return
+	}
+
+	total, err := b.chatService.CountActiveChats()
+	if err != nil {
+		b.sendAdminError(ctx, chatID, err)
+		return
+	}
+	broadcast.Status = database.BroadcastRunning
+	broadcast.Total = int(total)
+	if err := b.broadcastService.SaveBroadcast(broadcast); err != nil {
+		b.sendAdminError(ctx, chatID, err)
+		return
+	}
+
+	go b.runBroadcast(ctx, broadcast)
+}
+
+func (b *botService) discardBroadcast(ctx context.Context, chatID int64, id uint) {
+	broadcast, err := b.broadcastService.GetBroadcast(id)
+	if err != nil {
+		b.sendAdminError(ctx, chatID, err)
+		return
+	}
+	if broadcast.Status != database.BroadcastDraft {
+		return
+	}
+
+	broadcast.Status = database.BroadcastCancelled
+	if err := b.broadcastService.SaveBroadcast(broadcast); err != nil {
+		b.sendAdminError(ctx, chatID, err)
+		return
+	}
+	b.sendAdminMessage(ctx, chatID, fmt.Sprintf("🗑 Anunțul #%d a fost anulat.", id))
+}
+
+// broadcastCancelCommand stops every broadcast being sent
+func (b *botService) broadcastCancelCommand(ctx context.Context, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	broadcasts, err := b.broadcastService.GetRunningBroadcasts()
+	if err != nil {
+		b.sendAdminError(ctx, chatID, err)
+		return
+	}
+
+	// Persist the cancellation first so it survives a restart
+	for i := range broadcasts {
+		broadcasts[i].Status = database.BroadcastCancelled
+		if err := b.broadcastService.SaveBroadcast(&broadcasts[i]); err != nil {
+			b.sendAdminError(ctx, chatID, err)
+			return
+		}
+	}
+	stopped := b.broadcasts.cancelAll()
+
+	b.sendAdminMessage(ctx, chatID, fmt.Sprintf("⏹ <b>Anunțuri oprite:</b> %d", max(stopped, len(broadcasts))))
+}
+
+// resumeBroadcasts continues the broadcasts interrupted by a restart
+func (b *botService) resumeBroadcasts(ctx context.Context) {
+	broadcasts, err := b.broadcastService.GetRunningBroadcasts()
+	if err != nil {
+		fmt.Printf("Error loading running broadcasts: %v\n", err)
+		return
+	}
+
+	for i := range broadcasts {
+		fmt.Printf("Resuming broadcast #%d from chat %d\n", broadcasts[i].ID, broadcasts[i].Cursor)
+		go b.runBroadcast(ctx, &broadcasts[i])
+	}
+}
+
+// runBroadcast sends the announcement to every reachable chat after the
+// broadcast's cursor, throttled to broadcastRate messages per second, and
+// reports progress to the admin who created it
+func (b *botService) runBroadcast(ctx context.Context, broadcast *database.Broadcast) {
+	runCtx, ok := b.broadcasts.start(ctx, broadcast.ID)
+	if !ok {
+		return
+	}
+	defer b.broadcasts.finish(broadcast.ID)
+
+	progressID, err := b.bh.SendEditableMessage(ctx, broadcast.CreatedBy, formatBroadcastProgress(broadcast))
+	if err != nil {
+		fmt.Printf("Error sending broadcast progress: %v\n", err)
+	}
+	saveProgress := func() {
+		if err := b.broadcastService.SaveBroadcast(broadcast); err != nil {
+			fmt.Printf("Error saving broadcast #%d: %v\n", broadcast.ID, err)
+		}
+	}
+	reportProgress := func() {
+		saveProgress()
+		if progressID != 0 {
+			if err := b.bh.EditMessage(ctx, broadcast.CreatedBy, progressID, formatBroadcastProgress(broadcast)); err != nil {
+				fmt.Printf("Error updating broadcast progress: %v\n", err)
+			}
+		}
+	}
+
+	limiter := time.NewTicker(time.Second / broadcastRate)
+	defer limiter.Stop()
+
+	processed := 0
+	for {
+		chats, err := b.chatService.GetActiveChatsAfter(broadcast.Cursor, broadcastBatchSize)
+		if err != nil {
+			broadcast.LastError = err.Error()
+			reportProgress()
+			return
+		}
+		if len(chats) == 0 {
+			break
+		}
+
+		for _, chat := range chats {
+			select {
+			case <-runCtx.Done():
+				// A shutdown keeps the broadcast running so it resumes after restart
+				if ctx.Err() == nil {
+					broadcast.Status = database.BroadcastCancelled
+				}
+				reportProgress()
+				return
+			case <-limiter.C:
+			}
+
+			if !b.deliverBroadcast(runCtx, broadcast, chat.ChatID) {
+				continue
+			}
+			// The cursor is saved after every chat, so a broadcast resumed
+			// after a crash does not send the announcement twice
+			broadcast.Cursor = chat.ChatID
+
+			processed++
+			if processed%broadcastProgressEvery == 0 {
+				reportProgress()
+			} else {
+				saveProgress()
+			}
+		}
+	}
+
+	broadcast.Status = database.BroadcastCompleted
+	reportProgress()
+}
+
+// deliverBroadcast sends the announcement to a single chat, waiting and
+// retrying once when Telegram asks to slow down. It reports false when the
+// attempt was interrupted, so the chat is retried when the broadcast resumes.
+func (b *botService) deliverBroadcast(ctx context.Context, broadcast *database.Broadcast, chatID int64) bool {
+	err := b.bh.SendMessage(ctx, chatID, broadcast.Text)
+
+	var tooMany *bot.TooManyRequestsError
+	if errors.As(err, &tooMany) {
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Duration(tooMany.RetryAfter) * time.Second):
+			err = b.bh.SendMessage(ctx, chatID, broadcast.Text)
+		}
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+
+	switch {
+	case err == nil:
+		broadcast.Sent++
+	case errors.Is(err, bot.ErrorForbidden):
+		broadcast.Blocked++
+		if err := b.chatService.MarkBlocked(chatID); err != nil {
+			fmt.Printf("Error marking chat %d as blocked: %v\n", chatID, err)
+		}
+	default:
+		broadcast.Failed++
+		broadcast.LastError = err.Error()
+	}
+	return true
+}
+
+func formatBroadcastProgress(broadcast *database.Broadcast) string {
+	var title string
+	switch broadcast.Status {
+	case database.BroadcastCompleted:
+		title = fmt.Sprintf("✅ <b>Anunț #%d finalizat</b>", broadcast.ID)
+	case database.BroadcastCancelled:
+		title = fmt.Sprintf("⏹ <b>Anunț #%d oprit</b>", broadcast.ID)
+	default:
+		title = fmt.Sprintf("📤 <b>Anunț #%d în curs de trimitere...</b>", broadcast.ID)
+	}
+
+	var response strings.Builder
+	response.WriteString(title + "\n\n")
+	response.WriteString(fmt.Sprintf("Trimise: <b>%d</b>/%d\n", broadcast.Sent, broadcast.Total))
+	response.WriteString(fmt.Sprintf("Blocate: <b>%d</b>\n", broadcast.Blocked))
+	response.WriteString(fmt.Sprintf("Eșuate: <b>%d</b>\n", broadcast.Failed))
+	if broadcast.LastError != "" {
+		response.WriteString(fmt.Sprintf("Ultima eroare: <code>%s</code>\n", html.EscapeString(broadcast.LastError)))
+	}
+	if broadcast.Status == database.BroadcastRunning {
+		response.WriteString("\nFolosește /broadcast_cancel pentru a opri.")
+	}
+	return response.String()
+}
@@ -0,0 +1,93 @@
+package telegram_bot
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+)
+
+// broadcastBot delivers messages, calling interrupt instead for the chat
+// set in interruptAt
+type broadcastBot struct {
+	TelegramBot
+	delivered   []int64
+	interruptAt int64
+	interrupt   context.CancelFunc
+}
+
+func (f *broadcastBot) SendMessage(ctx context.Context, chatID int64, text string) error {
+	if chatID == f.interruptAt && f.interrupt != nil {
+		f.interrupt()
+		f.interrupt = nil
+		return ctx.Err()
+	}
+	f.delivered = append(f.delivered, chatID)
+	return nil
+}
+
+func (f *broadcastBot) SendEditableMessage(ctx context.Context, chatID int64, text string) (int, error) {
+	return 0, nil
+}
+
+type broadcastChats struct {
+	database.ChatService
+	ids []int64
+}
+
+func (f broadcastChats) GetActiveChatsAfter(chatID int64, limit int) ([]database.Chat, error) {
+	var chats []database.Chat
+	for _, id := range f.ids {
+		if id > chatID && len(chats) < limit {
+			chats = append(chats, database.Chat{ChatID: id})
+		}
+	}
+	return chats, nil
+}
+
+// broadcastStore keeps the saved broadcast, as the database would
+type broadcastStore struct {
+	database.BroadcastService
+	saved   database.Broadcast
+	cursors []int64
+}
+
+func (f *broadcastStore) SaveBroadcast(broadcast *database.Broadcast) error {
+	f.saved = *broadcast
+	f.cursors = append(f.cursors, broadcast.Cursor)
+	return nil
+}
+
+func TestBroadcastResumesAfterLastDeliveredChat(t *testing.T) {
+	telegram := &broadcastBot{interruptAt: 4}
+	store := &broadcastStore{}
+	b := &botService{
+		bh:               telegram,
+		chatService:      broadcastChats{ids: []int64{1, 2, 3, 4, 5}},
+		broadcastService: store,
+		broadcasts:       &broadcastRuns{cancels: make(map[uint]context.CancelFunc)},
+	}
+
+	// The process stops while sending to chat 4
+	ctx, cancel := context.WithCancel(context.Background())
+	telegram.interrupt = cancel
+	b.runBroadcast(ctx, &database.Broadcast{ID: 1, Status: database.BroadcastRunning, Cursor: 0})
+
+	if want := []int64{1, 2, 3}; len(store.cursors) < len(want) || !reflect.DeepEqual(store.cursors[:len(want)], want) {
+		t.Errorf("saved cursors %v, want every delivered chat saved in turn", store.cursors)
+	}
+	if store.saved.Cursor != 3 || store.saved.Status != database.BroadcastRunning {
+		t.Fatalf("saved cursor %d with status %s, want 3 and running", store.saved.Cursor, store.saved.Status)
+	}
+
+	resumed := store.saved
+	b.runBroadcast(context.Background(), &resumed)
+
+	if want := []int64{1, 2, 3, 4, 5}; !reflect.DeepEqual(telegram.delivered, want) {
+		t.Errorf("delivered to %v, want each chat once", telegram.delivered)
+	}
+	if store.saved.Status != database.BroadcastCompleted || store.saved.Sent != 5 {
+		t.Errorf("saved status %s with %d sent, want completed with 5", store.saved.Status, store.saved.Sent)
+	}
+}
@@ -0,0 +1,102 @@
+package telegram_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/go-telegram/bot/models"
+)
+
+const cmdEstimate = "estimare"
+
+// estimateCommand projects when a pending dossier will be resolved from the
+// recent pace of its year
+func (b *botService) estimateCommand(ctx context.Context, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	_, args, _ := strings.Cut(update.Message.Text, " ")
+	match, err := dossier.Parse(args)
+	if err != nil {
+		b.bh.SendMessage(ctx, chatID, estimateUsage)
+		return
+	}
+	number := match.Number
+
+	doc, err := b.processor.Document(number.Year)
+	if err != nil {
+		b.bh.SendMessage(ctx, chatID, fmt.Sprintf(errorMessage, html.EscapeString(err.Error())))
+		return
+	}
+
+	entry, ok := doc.Lookup(number)
+	switch {
+	case !ok:
+		b.bh.SendMessage(ctx, chatID, fmt.Sprintf(estimateNotFound, number))
+		return
+	case entry.State() == decree.StateFoundAndResolved:
+		b.bh.SendMessage(ctx, chatID, fmt.Sprintf(estimateResolved, number, html.EscapeString(entry.Order)))
+		return
+	case entry.State() != decree.StateFoundButNotResolved:
+		// Rejected and returned dossiers are closed, suspended ones are not moving
+		b.bh.SendMessage(ctx, chatID, fmt.Sprintf(estimateUnavailable, number, stateLabel(entry.State())))
+		return
+	}
+
+	now := time.Now()
+	stats := doc.Statistics(b.resolutionDates(number.Year), now)
+	estimate, err := stats.Estimate(doc, number, now)
+	if errors.Is(err, decree.ErrNoThroughput) {
+		b.bh.SendMessage(ctx, chatID, fmt.Sprintf(estimateNoThroughput, number, number.Year, decree.ThroughputWeeks))
+		return
+	}
+	if err != nil {
+		b.bh.SendMessage(ctx, chatID, fmt.Sprintf(errorMessage, html.EscapeString(err.Error())))
+		return
+	}
+
+	b.bh.SendMessage(ctx, chatID, formatEstimate(number, stats, estimate))
+}
+
+// resolutionDates returns when archived revisions first listed the order of
+// each dossier of a year; without them the estimate relies on the order dates
+func (b *botService) resolutionDates(year int) map[dossier.Number]time.Time {
+	dates, err := b.revisionService.GetResolutionDates(year)
+	if err != nil {
+		fmt.Printf("Error getting resolution dates: %v\n", err)
+		return nil
+	}
+
+	firstSeen := make(map[dossier.Number]time.Time, len(dates))
+	for decreeNumber, date := range dates {
+		if match, err := dossier.Parse(decreeNumber); err == nil {
+			firstSeen[match.Number] = date
+		}
+	}
+	return firstSeen
+}
+
+func formatEstimate(number dossier.Number, stats decree.Statistics, estimate decree.Estimate) string {
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("📈 <b>Estimare pentru</b> <code>%s</code>\n\n", number))
+	response.WriteString(fmt.Sprintf("📊 Ritm %d: ~<b>%.0f</b> dosare/săptămână (între %.0f și %.0f în ultimele %d săptămâni)\n",
+		stats.Year, stats.WeeklyRate, stats.LowRate, stats.HighRate, decree.ThroughputWeeks))
+	if stats.MedianWait > 0 {
+		response.WriteString(fmt.Sprintf("⏱ Durata mediană de la înregistrare la ordin: <b>%.0f luni</b>\n", stats.MedianWait.Hours()/24/30))
+	}
+	response.WriteString(fmt.Sprintf("⌛ Dosare înaintea ta, nerezolvate: <b>%d</b>\n\n", estimate.PendingBefore))
+
+	response.WriteString(fmt.Sprintf("📅 Cel mai probabil în jurul datei <b>%s</b>\n", estimate.Expected.Format("02.01.2006")))
+	response.WriteString(fmt.Sprintf("↔️ Interval: între <b>%s</b> și <b>%s</b>\n", estimate.Earliest.Format("02.01.2006"), estimate.Latest.Format("02.01.2006")))
+	if !estimate.ByMedianWait.IsZero() {
+		response.WriteString(fmt.Sprintf("🗓 După durata mediană: în jurul datei <b>%s</b>\n", estimate.ByMedianWait.Format("02.01.2006")))
+	}
+
+	response.WriteString("\n" + estimateDisclaimer)
+	return response.String()
+}
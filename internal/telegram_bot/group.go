@@ -0,0 +1,108 @@
+package telegram_bot
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// parseCommand splits a message into its command name and arguments.
+// Commands may carry the bot's username as a suffix (/abonamente@BotName),
+// which is how Telegram clients address commands in groups; commands
+// addressed to another bot are rejected.
+func (h *botHandler) parseCommand(text string) (string, []string, bool) {
+	parts := strings.Fields(text)
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "/") {
+		return "", nil, false
+	}
+
+	command, target, addressed := strings.Cut(strings.TrimPrefix(parts[0], "/"), "@")
+	if addressed && !strings.EqualFold(target, h.username) {
+		return "", nil, false
+	}
+
+	return strings.ToLower(command), parts[1:], true
+}
+
+// matchCommand returns a matcher for messages invoking the given command
+func (h *botHandler) matchCommand(cmd string) bot.MatchFunc {
+	return func(update *models.Update) bool {
+		if update.Message == nil {
+			return false
+		}
+		command, _, ok := h.parseCommand(update.Message.Text)
+		return ok && command == cmd
+	}
+}
+
+// isAddressed reports whether a message is meant for the bot. Every message
+// in a private chat is; in groups only mentions and replies to the bot are,
+// so regular chatter is ignored.
+func (h *botHandler) isAddressed(msg *models.Message) bool {
+	if !isGroupChat(msg.Chat) {
+		return true
+	}
+
+	// Documents carry their text in the caption
+	text := strings.ToLower(msg.Text + " " + msg.Caption)
+	if h.username != "" && strings.Contains(text, "@"+strings.ToLower(h.username)) {
+		return true
+	}
+
+	reply := msg.ReplyToMessage
+	return reply != nil && reply.From != nil && reply.From.ID == h.instance.ID()
+}
+
+// canManageSubscriptions reports whether the sender may change the chat's
+// subscriptions. Group subscriptions are reserved to the group's admins.
+func (h *botHandler) canManageSubscriptions(ctx context.Context, msg *models.Message) bool {
+	if !isGroupChat(msg.Chat) {
+		return true
+	}
+
+	// Anonymous admins post on behalf of the group itself
+	if msg.SenderChat != nil && msg.SenderChat.ID == msg.Chat.ID {
+		return true
+	}
+	if msg.From == nil {
+		return false
+	}
+
+	member, err := h.instance.GetChatMember(ctx, &bot.GetChatMemberParams{
+		ChatID: msg.Chat.ID,
+		UserID: msg.From.ID,
+	})
+	if err != nil {
+		log.Printf("Error getting chat member %d in chat %d: %v", msg.From.ID, msg.Chat.ID, err)
+		return false
+	}
+
+	return member.Type == models.ChatMemberTypeOwner || member.Type == models.ChatMemberTypeAdministrator
+}
+
+// handleMigration moves the chat's subscriptions, settings and history to the
+// new chat when a group is upgraded to a supergroup
+func (h *botHandler) handleMigration(msg *models.Message) {
+	if msg.MigrateToChatID == 0 {
+		return
+	}
+
+	if err := h.subscriptionService.MigrateChat(msg.Chat.ID, msg.MigrateToChatID); err != nil {
+		log.Printf("Error migrating chat %d to %d: %v", msg.Chat.ID, msg.MigrateToChatID, err)
+		return
+	}
+	log.Printf("Migrated chat %d to %d", msg.Chat.ID, msg.MigrateToChatID)
+}
+
+func isGroupChat(chat models.Chat) bool {
+	return chat.Type == models.ChatTypeGroup || chat.Type == models.ChatTypeSupergroup
+}
+
+// isGroupChatID reports whether a chat ID belongs to a group; Telegram uses
+// negative identifiers for groups and channels
+func isGroupChatID(chatID int64) bool {
+	return chatID < 0
+}
@@ -0,0 +1,150 @@
+package telegram_bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	cmdHistory = "istoric"
+
+	// historySize is the number of lookups shown by /istoric
+	historySize = 10
+	// maxRecheckButtons limits the re-check buttons under the history
+	maxRecheckButtons = 5
+)
+
+// recordLookups adds successful lookups to the chat's history together with
+// the revision of the PDF they were found in
+func (b *botService) recordLookups(chatID int64, results []decree.Result) {
+	revisions := make(map[int]string)
+	for _, source := range b.processor.Sources() {
+		revisions[source.Year] = source.Hash
+	}
+
+	lookups := make([]database.Lookup, 0, len(results))
+	for _, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		lookup := database.Lookup{
+			ChatID:       chatID,
+			DecreeNumber: result.DecreeNumber,
+			State:        int(result.State),
+		}
+		if match, err := dossier.Parse(result.DecreeNumber); err == nil {
+			lookup.Revision = revisions[match.Number.Year]
+		}
+		lookups = append(lookups, lookup)
+	}
+
+	if err := b.lookupService.RecordLookups(lookups); err != nil {
+		fmt.Printf("Error recording lookups for chat %d: %v\n", chatID, err)
+	}
+}
+
+// historyCommand shows the chat's recent lookups with buttons to check them again
+func (b *botService) historyCommand(ctx context.Context, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	lookups, err := b.lookupService.GetRecentLookups(chatID, historySize)
+	if err != nil {
+		fmt.Printf("Error getting lookup history for chat %d: %v\n", chatID, err)
+		b.bh.SendMessage(ctx, chatID, "❌ <b>Eroare la obținerea istoricului</b>\n\nTe rugăm să încerci din nou mai târziu.")
+		return
+	}
+	if len(lookups) == 0 {
+		b.bh.SendMessage(ctx, chatID, historyEmpty)
+		return
+	}
+
+	profile, err := b.profileService.GetProfile(chatID)
+	if err != nil {
+		fmt.Printf("Error getting profile for chat %d: %v\n", chatID, err)
+		profile = &database.Profile{}
+	}
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf(historyTitle, len(lookups)))
+	var buttons []Button
+	seen := make(map[string]bool)
+	for _, lookup := range lookups {
+		response.WriteString(fmt.Sprintf("• %s — <code>%s</code> — %s",
+			lookup.CreatedAt.In(profile.Location()).Format("02.01 15:04"), lookup.DecreeNumber, stateLabel(decree.FindState(lookup.State))))
+		if len(lookup.Revision) >= 8 {
+			response.WriteString(fmt.Sprintf(" <i>(rev. %s)</i>", lookup.Revision[:8]))
+		}
+		response.WriteString("\n")
+
+		if seen[lookup.DecreeNumber] || len(buttons) == maxRecheckButtons {
+			continue
+		}
+		seen[lookup.DecreeNumber] = true
+		match, err := dossier.Parse(lookup.DecreeNumber)
+		if err != nil {
+			continue
+		}
+		buttons = append(buttons, Button{Text: "🔄 " + lookup.DecreeNumber, OnSelect: func(ctx context.Context, chatID int64) {
+			b.handleDecreeRequest(ctx, chatID, match)
+		}})
+	}
+	response.WriteString(historyFooter)
+
+	rows := make([][]Button, len(buttons))
+	for i, button := range buttons {
+		rows[i] = []Button{button}
+	}
+	if err := b.bh.SendMessageWithButtonRows(ctx, chatID, response.String(), rows); err != nil {
+		fmt.Printf("Error sending lookup history: %v\n", err)
+	}
+}
+
+// stateLabel is the short description of a dossier state used in lists
+func stateLabel(state decree.FindState) string {
+	switch state {
+	case decree.StateFoundAndResolved:
+		return "✅ " + stateName(state)
+	case decree.StateFoundButNotResolved:
+		return "⏳ " + stateName(state)
+	case decree.StateAwaitingOath:
+		return "🕊 " + stateName(state)
+	case decree.StateOathScheduled:
+		return "🏛 " + stateName(state)
+	case decree.StateRejected:
+		return "❌ " + stateName(state)
+	case decree.StateReturned:
+		return "↩️ " + stateName(state)
+	case decree.StateSuspended:
+		return "⏸ " + stateName(state)
+	default:
+		return "🔎 " + stateName(state)
+	}
+}
+
+// stateName is the plain description of a dossier state, e.g. for documents
+func stateName(state decree.FindState) string {
+	switch state {
+	case decree.StateFoundAndResolved:
+		return "rezolvat"
+	case decree.StateFoundButNotResolved:
+		return "în procesare"
+	case decree.StateAwaitingOath:
+		return "rezolvat, așteaptă programarea la jurământ"
+	case decree.StateOathScheduled:
+		return "programat la jurământ"
+	case decree.StateRejected:
+		return "respins"
+	case decree.StateReturned:
+		return "restituit"
+	case decree.StateSuspended:
+		return "suspendat"
+	default:
+		return "negăsit"
+	}
+}
@@ -0,0 +1,213 @@
+package telegram_bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	// inlineLookupBudget keeps inline answers well within Telegram's timeout
+	inlineLookupBudget = 5 * time.Second
+	// inlineStateTTL is how long a looked up state is reused for inline answers
+	inlineStateTTL = 10 * time.Minute
+	// inlineCacheTime is how long (in seconds) Telegram may cache a complete answer
+	inlineCacheTime = 300
+	// maxInlineResults limits the dossiers answered for a single inline query
+	maxInlineResults = 5
+)
+
+// AnswerInlineQuery answers an inline query with the given results
+func (h *botHandler) AnswerInlineQuery(ctx context.Context, queryID string, results []models.InlineQueryResult, cacheTime int) error {
+	_, err := h.instance.AnswerInlineQuery(ctx, &bot.AnswerInlineQueryParams{
+		InlineQueryID: queryID,
+		Results:       results,
+		CacheTime:     cacheTime,
+	})
+	return err
+}
+
+// handleInlineQuery answers "@bot 123/RD/2023" queries typed in any chat
+// with a result card the user can send into that chat
+func (b *botService) handleInlineQuery(ctx context.Context, query *models.InlineQuery) {
+	matches := dossier.Extract(query.Query)
+	if len(matches) == 0 {
+		help := []models.InlineQueryResult{newInlineArticle("help", inlineHelpTitle, inlineHelpDescription, inlineHelpMsg)}
+		if err := b.bh.AnswerInlineQuery(ctx, query.ID, help, inlineCacheTime); err != nil {
+			fmt.Printf("Error answering inline query: %v\n", err)
+		}
+		return
+	}
+	if len(matches) > maxInlineResults {
+		matches = matches[:maxInlineResults]
+	}
+
+	states := b.inlineStates(matches)
+
+	results := make([]models.InlineQueryResult, 0, len(matches))
+	cacheTime := inlineCacheTime
+	for _, match := range matches {
+		decreeNumber := match.Canonical()
+		state, ok := states[decreeNumber]
+		if !ok {
+			// Do not let Telegram cache a "checking..." card
+			cacheTime = 0
+			results = append(results, newInlineArticle(decreeNumber+"-checking",
+				fmt.Sprintf(inlineCheckingTitle, decreeNumber), inlineCheckingDescription,
+				fmt.Sprintf(inlineCheckingMsg, decreeNumber)))
+			continue
+		}
+		results = append(results, inlineStateArticle(decreeNumber, state))
+	}
+
+	if err := b.bh.AnswerInlineQuery(ctx, query.ID, results, cacheTime); err != nil {
+		fmt.Printf("Error answering inline query: %v\n", err)
+	}
+}
+
+// inlineLookups tracks the inline lookups running in the background, so
+// queries typed while a dossier is being looked up wait for that lookup
+// instead of starting another one
+type inlineLookups struct {
+	mu      sync.Mutex
+	pending map[string]chan struct{}
+}
+
+// start returns a channel closed when the dossier's lookup finishes and
+// whether the caller has to run that lookup
+func (l *inlineLookups) start(decreeNumber string) (<-chan struct{}, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if done, running := l.pending[decreeNumber]; running {
+		return done, false
+	}
+	done := make(chan struct{})
+	l.pending[decreeNumber] = done
+	return done, true
+}
+
+func (l *inlineLookups) finish(decreeNumbers []string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, decreeNumber := range decreeNumbers {
+		if done, ok := l.pending[decreeNumber]; ok {
+			close(done)
+			delete(l.pending, decreeNumber)
+		}
+	}
+}
+
+// inlineStates resolves dossier states from the inline answer cache, falling
+// back to a lookup bounded by inlineLookupBudget. A lookup that exceeds the
+// budget keeps running and fills the cache for the next query; dossiers
+// already being looked up wait for the running lookup.
+func (b *botService) inlineStates(matches []dossier.Match) map[string]decree.FindState {
+	states := make(map[string]decree.FindState, len(matches))
+	var missing, started []string
+	var waits []<-chan struct{}
+
+	for _, match := range matches {
+		decreeNumber := match.Canonical()
+		if state, found := b.cachedInlineState(decreeNumber); found {
+			states[decreeNumber] = state
+			continue
+		}
+		missing = append(missing, decreeNumber)
+		done, owner := b.inlineLookups.start(decreeNumber)
+		if owner {
+			started = append(started, decreeNumber)
+		}
+		waits = append(waits, done)
+	}
+
+	if len(started) > 0 {
+		go func() {
+			defer b.inlineLookups.finish(started)
+			results, _, err := b.processor.HandleMany(started)
+			if err != nil {
+				fmt.Printf("Error processing inline lookup: %v\n", err)
+			}
+			for _, result := range results {
+				if result.Err == nil {
+					b.inlineCache.Set(result.DecreeNumber, []byte(strconv.Itoa(int(result.State))))
+				}
+			}
+		}()
+	}
+
+	deadline := time.After(inlineLookupBudget)
+wait:
+	for _, done := range waits {
+		select {
+		case <-done:
+		case <-deadline:
+			break wait
+		}
+	}
+
+	for _, decreeNumber := range missing {
+		if state, found := b.cachedInlineState(decreeNumber); found {
+			states[decreeNumber] = state
+		}
+	}
+	return states
+}
+
+// cachedInlineState returns the state of a dossier looked up recently
+func (b *botService) cachedInlineState(decreeNumber string) (decree.FindState, bool) {
+	data, found := b.inlineCache.Get(decreeNumber)
+	if !found {
+		return decree.StateNotFound, false
+	}
+	state, err := strconv.Atoi(string(data))
+	if err != nil {
+		return decree.StateNotFound, false
+	}
+	return decree.FindState(state), true
+}
+
+// inlineStateArticle builds the result card for a dossier with a known state
+func inlineStateArticle(decreeNumber string, state decree.FindState) models.InlineQueryResult {
+	id := fmt.Sprintf("%s-%d", decreeNumber, state)
+	switch state {
+	case decree.StateFoundAndResolved:
+		return newInlineArticle(id, fmt.Sprintf(inlineResolvedTitle, decreeNumber), inlineResolvedDescription,
+			fmt.Sprintf(inlineResolvedMsg, decreeNumber))
+	case decree.StateFoundButNotResolved:
+		return newInlineArticle(id, fmt.Sprintf(inlineInProgressTitle, decreeNumber), inlineInProgressDescription,
+			fmt.Sprintf(inlineInProgressMsg, decreeNumber))
+	case decree.StateRejected:
+		return newInlineArticle(id, fmt.Sprintf(inlineRejectedTitle, decreeNumber), inlineRejectedDescription,
+			fmt.Sprintf(inlineRejectedMsg, decreeNumber))
+	case decree.StateReturned:
+		return newInlineArticle(id, fmt.Sprintf(inlineReturnedTitle, decreeNumber), inlineReturnedDescription,
+			fmt.Sprintf(inlineReturnedMsg, decreeNumber))
+	case decree.StateSuspended:
+		return newInlineArticle(id, fmt.Sprintf(inlineSuspendedTitle, decreeNumber), inlineSuspendedDescription,
+			fmt.Sprintf(inlineSuspendedMsg, decreeNumber))
+	default:
+		return newInlineArticle(id, fmt.Sprintf(inlineNotFoundTitle, decreeNumber), inlineNotFoundDescription,
+			fmt.Sprintf(inlineNotFoundMsg, decreeNumber))
+	}
+}
+
+func newInlineArticle(id, title, description, text string) models.InlineQueryResult {
+	return &models.InlineQueryResultArticle{
+		ID:          id,
+		Title:       title,
+		Description: description,
+		InputMessageContent: &models.InputTextMessageContent{
+			MessageText: text,
+			ParseMode:   models.ParseModeHTML,
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package telegram_bot
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/cache"
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/andiq123/cetatenie-analyzer/internal/timer"
+)
+
+// slowProcessor holds lookups until release is closed and counts the
+// lookups of each dossier
+type slowProcessor struct {
+	decree.Processor
+	release chan struct{}
+	mu      sync.Mutex
+	lookups map[string]int
+}
+
+func (p *slowProcessor) HandleMany(searches []string) ([]decree.Result, *timer.TimeReport, error) {
+	p.mu.Lock()
+	for _, search := range searches {
+		p.lookups[search]++
+	}
+	p.mu.Unlock()
+
+	<-p.release
+	results := make([]decree.Result, len(searches))
+	for i, search := range searches {
+		results[i] = decree.Result{DecreeNumber: search, State: decree.StateFoundAndResolved}
+	}
+	return results, &timer.TimeReport{}, nil
+}
+
+func TestInlineStatesShareRunningLookups(t *testing.T) {
+	processor := &slowProcessor{release: make(chan struct{}), lookups: make(map[string]int)}
+	b := &botService{
+		processor:     processor,
+		inlineCache:   cache.New(inlineStateTTL),
+		inlineLookups: &inlineLookups{pending: make(map[string]chan struct{})},
+	}
+
+	// Users type a query letter by letter: each keystroke is a new query
+	queries := [][]dossier.Match{
+		dossier.Extract("1/RD/2023"),
+		dossier.Extract("1/RD/2023 2/RD/2023"),
+		dossier.Extract("1/RD/2023 2/RD/2023"),
+	}
+	var wg sync.WaitGroup
+	states := make([]map[string]decree.FindState, len(queries))
+	for i, matches := range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			states[i] = b.inlineStates(matches)
+		}()
+		// The next query starts once this one registered its lookups
+		for {
+			b.inlineLookups.mu.Lock()
+			registered := len(b.inlineLookups.pending) >= len(matches)
+			b.inlineLookups.mu.Unlock()
+			if registered {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	close(processor.release)
+	wg.Wait()
+
+	for number, count := range processor.lookups {
+		if count != 1 {
+			t.Errorf("%s looked up %d times, want once", number, count)
+		}
+	}
+	if len(processor.lookups) != 2 {
+		t.Errorf("looked up %v, want both dossiers", processor.lookups)
+	}
+	for i, matches := range queries {
+		if len(states[i]) != len(matches) {
+			t.Errorf("query %d answered %v, want every dossier", i, states[i])
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package telegram_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	cmdLabel = "eticheta"
+	cmdNote  = "nota"
+
+	maxLabelLength = 32
+	maxNoteLength  = 200
+)
+
+// labelCommand sets or clears the label of a subscription: /eticheta 123/RD/2023 Mama
+func (h *botHandler) labelCommand(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	if !h.canManageSubscriptions(ctx, update.Message) {
+		h.SendMessage(ctx, chatID, groupAdminOnly)
+		return
+	}
+
+	decreeNumber, label, ok := parseSubscriptionText(update.Message.Text)
+	if !ok {
+		h.SendMessage(ctx, chatID, labelUsage)
+		return
+	}
+	// Labels are shown on one line next to the dossier number
+	label = strings.Join(strings.Fields(label), " ")
+	if utf8.RuneCountInString(label) > maxLabelLength {
+		h.SendMessage(ctx, chatID, fmt.Sprintf(labelTooLong, maxLabelLength))
+		return
+	}
+
+	h.updateLabel(ctx, chatID, decreeNumber, label)
+}
+
+// noteCommand sets or clears the note of a subscription: /nota 123/RD/2023 text
+func (h *botHandler) noteCommand(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	if !h.canManageSubscriptions(ctx, update.Message) {
+		h.SendMessage(ctx, chatID, groupAdminOnly)
+		return
+	}
+
+	decreeNumber, note, ok := parseSubscriptionText(update.Message.Text)
+	if !ok {
+		h.SendMessage(ctx, chatID, noteUsage)
+		return
+	}
+	if utf8.RuneCountInString(note) > maxNoteLength {
+		h.SendMessage(ctx, chatID, fmt.Sprintf(noteTooLong, maxNoteLength))
+		return
+	}
+
+	err := h.subscriptionService.SetNote(chatID, decreeNumber, note)
+	if h.reportSubscriptionUpdate(ctx, chatID, decreeNumber, err) {
+		return
+	}
+
+	if note == "" {
+		h.SendMessage(ctx, chatID, fmt.Sprintf("✅ <b>Notiță ștearsă</b>\n\nDosarul <code>%s</code> nu mai are o notiță.", decreeNumber))
+		return
+	}
+	h.SendMessage(ctx, chatID, fmt.Sprintf("✅ <b>Notiță salvată</b>\n\n<code>%s</code>\n📝 <i>%s</i>", decreeNumber, html.EscapeString(note)))
+}
+
+func (h *botHandler) updateLabel(ctx context.Context, chatID int64, decreeNumber, label string) {
+	err := h.subscriptionService.SetLabel(chatID, decreeNumber, label)
+	if h.reportSubscriptionUpdate(ctx, chatID, decreeNumber, err) {
+		return
+	}
+
+	if label == "" {
+		h.SendMessage(ctx, chatID, fmt.Sprintf("✅ <b>Etichetă ștearsă</b>\n\nDosarul <code>%s</code> nu mai are o etichetă.", decreeNumber))
+		return
+	}
+	subscription := database.Subscription{DecreeNumber: decreeNumber, Label: label}
+	h.SendMessage(ctx, chatID, fmt.Sprintf("✅ <b>Etichetă salvată</b>\n\n%s", formatSubscription(subscription)))
+}
+
+// reportSubscriptionUpdate tells the user why a subscription could not be
+// changed and reports whether there was an error
+func (h *botHandler) reportSubscriptionUpdate(ctx context.Context, chatID int64, decreeNumber string, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, database.ErrSubscriptionNotFound):
+		h.SendMessage(ctx, chatID, fmt.Sprintf(notSubscribedTo, decreeNumber))
+	default:
+		log.Printf("Error updating subscription %s for chat %d: %v", decreeNumber, chatID, err)
+		h.SendMessage(ctx, chatID, "❌ <b>Eroare la actualizarea abonamentului</b>\n\nTe rugăm să încerci din nou mai târziu.")
+	}
+	return true
+}
+
+// parseSubscriptionText splits "/comanda 123/RD/2023 text" into the
+// canonical dossier number (or range) and the (possibly empty) text, which
+// keeps its line breaks
+func parseSubscriptionText(message string) (string, string, bool) {
+	parts := strings.Fields(message)
+	if len(parts) < 2 {
+		return "", "", false
+	}
+
+	args := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message), parts[0]))
+	if r, text, err := dossier.ParseRange(args); err == nil {
+		return r.String(), text, true
+	}
+
+	match, text, err := dossier.ParseLeading(args)
+	if err != nil {
+		return "", "", false
+	}
+	return match.Canonical(), text, true
+}
+
+// formatSubscription shows a subscription's title with the state worth showing
+func formatSubscription(subscription database.Subscription) string {
+	text := subscription.Title()
+	// Suspended dossiers and resolved ones waiting for their oath stay
+	// subscribed, their state is worth showing
+	if subscription.LastState != nil {
+		if state := decree.FindState(*subscription.LastState); state == decree.StateSuspended || state == decree.StateAwaitingOath || state == decree.StateOathScheduled {
+			text += " — " + stateLabel(state)
+		}
+	}
+	return text
+}
@@ -0,0 +1,27 @@
+package telegram_bot
+
+import "testing"
+
+func TestParseSubscriptionText(t *testing.T) {
+	tests := []struct {
+		message string
+		number  string
+		text    string
+		ok      bool
+	}{
+		{message: "/eticheta 123/RD/2023 Mama", number: "123/RD/2023", text: "Mama", ok: true},
+		{message: "/eticheta 123 / rd / 2023", number: "123/RD/2023", ok: true},
+		{message: "/nota 123/RD/2023 acte depuse\nde trimis cazierul\n\n- pașaport", number: "123/RD/2023", text: "acte depuse\nde trimis cazierul\n\n- pașaport", ok: true},
+		{message: "/nota@cetatenie_bot   123/RD/2023   două  spații ", number: "123/RD/2023", text: "două  spații", ok: true},
+		{message: "/nota 100-200/RD/2023 lot\nfamilie", number: "100-200/RD/2023", text: "lot\nfamilie", ok: true},
+		{message: "/nota", ok: false},
+		{message: "/nota Mama 123/RD/2023", ok: false},
+	}
+
+	for _, tt := range tests {
+		number, text, ok := parseSubscriptionText(tt.message)
+		if ok != tt.ok || number != tt.number || text != tt.text {
+			t.Errorf("parseSubscriptionText(%q) = %q, %q, %v, want %q, %q, %v", tt.message, number, text, ok, tt.number, tt.text, tt.ok)
+		}
+	}
+}
@@ -1,8 +1,7 @@
 package telegram_bot
 
 const (
-	decreePattern = `^\d{1,5}/RD/\d{4}$`
-	startMessage  = `🌟 <b>Bun venit la Cetățenie Analyzer!</b> 🇷🇴
+	startMessage = `🌟 <b>Bun venit la Cetățenie Analyzer!</b> 🇷🇴
 
 Cu acest bot poți verifica starea dosarului tău de redobândire a cetățeniei române și să primești notificări când se schimbă starea. 
 
@@ -15,6 +14,8 @@ Cu acest bot poți verifica starea dosarului tău de redobândire a cetățeniei
 
 Succes în procesul tău! 🍀`
 
+	correctionNotice = "✏️ <b>Am corectat numărul</b> <code>%s</code> → <code>%s</code>\n<i>%s</i>\n\n"
+
 	invalidFormat  = "❌ <b>Format invalid</b>\n\nTe rog folosește formatul: <b>[număr]/RD/[an]</b>\nExemplu: <code>123/RD/2023</code>"
 	searching      = "🔍 <b>Căutare în curs...</b>\n\nDosar: <code>%s</code>\n\nTe rog așteaptă puțin."
 	errorMessage   = "⚠️ <b>A apărut o eroare</b>\n\n<code>%s</code>\n\nTe rugăm să încerci din nou mai târziu."
@@ -27,17 +28,135 @@ Succes în procesul tău! 🍀`
 	inProgressMsg = "⏳ <b>Dosar în procesare</b>\n\nDosarul <code>%s</code> a fost <b>găsit dar nu este rezolvat încă</b>.\n\n" +
 		"⏱️ Timp preluare date: %s\n" +
 		"⏱️ Timp analiză document: %s\n\n" +
+		"%s" +
 		"Va trebui să mai aștepți până când va fi finalizat."
 
+	queuePositionMsg = "📊 <b>Poziția ta în %d</b>\n" +
+		"• Dosare înregistrate înaintea ta, încă nerezolvate: <b>%d</b>\n" +
+		"• Cel mai mare număr rezolvat: <b>%s</b>\n" +
+		"• Dosare rezolvate: <b>%.1f%%</b> (%d din %d)\n"
+	queueOvertakenMsg = "ℹ️ Au fost rezolvate și dosare cu numere mai mari decât al tău; ordinea de soluționare nu este strictă.\n"
+
+	rejectedMsg = "❌ <b>Dosar respins</b>\n\nDosarul <code>%s</code> apare în listă cu soluția <b>respins</b>.\n\n" +
+		"⏱️ Timp preluare date: %s\n" +
+		"⏱️ Timp analiză document: %s\n\n" +
+		"%s" +
+		"Pentru motivele respingerii și căile de atac te rugăm să contactezi autoritățile competente."
+
+	returnedMsg = "↩️ <b>Dosar restituit</b>\n\nDosarul <code>%s</code> apare în listă ca <b>restituit</b>.\n\n" +
+		"⏱️ Timp preluare date: %s\n" +
+		"⏱️ Timp analiză document: %s\n\n" +
+		"%s" +
+		"Dosarul a fost returnat solicitantului; te rugăm să contactezi autoritățile competente pentru detalii."
+
+	suspendedMsg = "⏸ <b>Dosar suspendat</b>\n\nDosarul <code>%s</code> apare în listă ca <b>suspendat</b>.\n\n" +
+		"⏱️ Timp preluare date: %s\n" +
+		"⏱️ Timp analiză document: %s\n\n" +
+		"%s" +
+		"Soluționarea este oprită până la reluarea procedurii; poți adăuga dosarul la notificări pentru a afla când se schimbă starea."
+
+	// solutionLine shows the solution column of the dossier's row
+	solutionLine = "📄 Mențiune: <i>%s</i>\n\n"
+
 	notFoundMsg = "🔎 <b>Rezultat negativ</b>\n\nDosarul <code>%s</code> <b>nu a fost găsit</b>.\n\n" +
 		"⏱️ Timp preluare date: %s\n" +
 		"⏱️ Timp analiză document: %s\n\n" +
 		"Te rugăm să verifici numărul și anul, sau să contactezi autoritățile competente."
 
+	multiSearching   = "🔍 <b>Căutare în curs...</b>\n\nVerific <b>%d</b> dosare.\n\nTe rog așteaptă puțin."
+	tooManyDecrees   = "⚠️ <b>Prea multe dosare</b>\n\nPoți verifica cel mult <b>%d</b> dosare într-un singur mesaj. Am găsit <b>%d</b>."
+	multiResultTitle = "📋 <b>Rezultate pentru %d dosare</b>\n\n"
+	multiSummary     = "\n✅ Rezolvate: <b>%d</b>\n⏳ În procesare: <b>%d</b>\n❌ Respinse sau restituite: <b>%d</b>\n🔎 Negăsite: <b>%d</b>\n⚠️ Erori: <b>%d</b>\n\n" +
+		"⏱️ Timp preluare date: %s\n" +
+		"⏱️ Timp analiză document: %s"
+	groupAdminOnly     = "🔒 <b>Acțiune rezervată administratorilor</b>\n\nDoar administratorii grupului pot modifica abonamentele grupului."
+	groupSubscribeHint = "\n\n👥 Administratorii grupului pot adăuga dosarul la notificări cu <code>/adauga %s</code>"
+	correctionLine     = "✏️ <code>%s</code> → <code>%s</code>\n"
+
+	inlineHelpTitle       = "Verifică un dosar"
+	inlineHelpDescription = "Scrie numărul dosarului, ex: 123/RD/2023"
+	inlineHelpMsg         = "ℹ️ Poți verifica starea unui dosar de cetățenie din orice conversație scriind numele botului urmat de numărul dosarului, ex: <code>123/RD/2023</code>"
+
+	inlineResolvedTitle         = "✅ %s — rezolvat"
+	inlineResolvedDescription   = "Dosarul a fost găsit și rezolvat"
+	inlineResolvedMsg           = "🎉 Dosarul <code>%s</code> a fost <b>găsit și rezolvat</b>."
+	inlineInProgressTitle       = "⏳ %s — în procesare"
+	inlineInProgressDescription = "Dosarul a fost găsit dar nu este rezolvat încă"
+	inlineInProgressMsg         = "⏳ Dosarul <code>%s</code> a fost <b>găsit dar nu este rezolvat încă</b>."
+	inlineRejectedTitle         = "❌ %s — respins"
+	inlineRejectedDescription   = "Dosarul apare în listă cu soluția respins"
+	inlineRejectedMsg           = "❌ Dosarul <code>%s</code> apare în listă cu soluția <b>respins</b>."
+	inlineReturnedTitle         = "↩️ %s — restituit"
+	inlineReturnedDescription   = "Dosarul a fost restituit solicitantului"
+	inlineReturnedMsg           = "↩️ Dosarul <code>%s</code> apare în listă ca <b>restituit</b>."
+	inlineSuspendedTitle        = "⏸ %s — suspendat"
+	inlineSuspendedDescription  = "Soluționarea dosarului este suspendată"
+	inlineSuspendedMsg          = "⏸ Dosarul <code>%s</code> apare în listă ca <b>suspendat</b>."
+	inlineNotFoundTitle         = "🔎 %s — negăsit"
+	inlineNotFoundDescription   = "Dosarul nu apare în documentele publicate"
+	inlineNotFoundMsg           = "🔎 Dosarul <code>%s</code> <b>nu a fost găsit</b> în documentele publicate."
+	inlineCheckingTitle         = "🔍 %s — se verifică..."
+	inlineCheckingDescription   = "Verificarea durează mai mult, încearcă din nou în câteva secunde"
+	inlineCheckingMsg           = "🔍 Starea dosarului <code>%s</code> este în curs de verificare. Încearcă din nou în câteva secunde."
+
+	labelTooLong    = "❌ <b>Etichetă prea lungă</b>\n\nEticheta poate avea cel mult %d caractere."
+	noteTooLong     = "❌ <b>Notiță prea lungă</b>\n\nNotița poate avea cel mult %d caractere."
+	labelUsage      = "❌ <b>Format invalid</b>\n\nExemplu: <code>/eticheta 123/RD/2023 Mama</code>\nTrimite doar numărul dosarului pentru a șterge eticheta."
+	noteUsage       = "❌ <b>Format invalid</b>\n\nExemplu: <code>/nota 123/RD/2023 depus la Chișinău</code>\nTrimite doar numărul dosarului pentru a șterge notița."
+	notSubscribedTo = "ℹ️ <b>Abonament inexistent</b>\n\nNu ești abonat la dosarul <code>%s</code>. Folosește /adauga pentru a-l adăuga."
+
+	exportDataCaption     = "📦 <b>Datele tale</b>\n\nAcesta este tot ce am stocat despre această conversație."
+	eraseDataWarning      = "⚠️ <b>Ștergerea datelor</b>\n\nVom șterge definitiv abonamentele, etichetele, notițele, istoricul căutărilor, setările și notificările în așteptare ale acestei conversații. Acțiunea nu poate fi anulată."
+	eraseDataGroupConfirm = "\n\nTrimite <code>/sterge_datele %s</code> pentru a confirma."
+	eraseDataDone         = "🧹 <b>Datele au fost șterse</b>\n\nNu mai stocăm nimic despre această conversație. Dacă ne scrii din nou, vom reține doar datele necesare pentru a-ți răspunde."
+
+	historyTitle  = "🕘 <b>Ultimele tale căutări (%d)</b>\n\n"
+	historyFooter = "\nApasă pe un dosar pentru a-l verifica din nou."
+	historyEmpty  = "📭 <b>Nu ai nicio căutare în istoric</b>\n\nTrimite un număr de dosar în formatul <b>[număr]/RD/[an]</b> pentru a-l verifica."
+
+	importFailed  = "❌ <b>Importul a eșuat</b>\n\n%s\n\nTrimite un fișier .csv cu numărul dosarului și, opțional, eticheta pe fiecare rând, sau un fișier .json cu o listă de dosare."
+	importReport  = "📥 <b>Import finalizat</b> (%d rânduri)\n\n✅ Adăugate: <b>%d</b>\n🏷 Etichete actualizate: <b>%d</b>\nℹ️ Existente deja: <b>%d</b>\n❌ Respinse: <b>%d</b>"
+	exportCaption = "📤 <b>Abonamentele tale</b> (%d)\n\nFișierul poate fi modificat și trimis înapoi pentru import."
+
+	reportUsage      = "❌ <b>Niciun dosar pentru raport</b>\n\nScrie dosarele după comandă, de exemplu <code>/raport 123/RD/2023 456/RD/2022</code>, sau adaugă abonamente pentru a primi raportul lor."
+	reportTooMany    = "⚠️ <b>Prea multe dosare pentru raport</b>\n\nUn raport poate cuprinde cel mult <b>%d</b> dosare, iar lista are <b>%d</b>. Împarte dosarele în mai multe rapoarte, de exemplu <code>/raport 123/RD/2023 456/RD/2022</code>."
+	reportGenerating = "📑 Se generează raportul pentru %d dosare..."
+	reportCaption    = "📑 <b>Raport dosare</b> (%d)\n\n✅ Rezolvate: %d\n⏳ În procesare: %d\n❌ Respinse sau restituite: %d\n🔎 Negăsite: %d\n⚠️ Erori: %d\n\nDeschide fișierul HTML în browser pentru a-l tipări sau salva ca PDF."
+
+	estimateUsage        = "❌ <b>Format invalid</b>\n\nScrie dosarul după comandă, de exemplu <code>/estimare 123/RD/2023</code>"
+	estimateNotFound     = "🔎 Dosarul <code>%s</code> <b>nu a fost găsit</b>, nu se poate face o estimare.\n\nTe rugăm să verifici numărul și anul."
+	estimateResolved     = "🎉 Dosarul <code>%s</code> <b>este deja rezolvat</b> (ordin %s)."
+	estimateUnavailable  = "ℹ️ Dosarul <code>%s</code> apare în listă ca <b>%s</b>, nu se poate face o estimare a soluționării."
+	estimateNoThroughput = "📈 <b>Nu se poate face o estimare</b> pentru <code>%s</code>\n\nNiciun dosar din %d nu a fost rezolvat în ultimele %d săptămâni."
+	estimateDisclaimer   = "ℹ️ <i>Estimarea presupune că dosarele sunt soluționate în ordinea înregistrării, în ritmul din ultimele săptămâni. Data înregistrării este aproximată din numărul dosarului.</i>"
+
+	statisticsUsage = "❌ <b>An invalid</b>\n\nExemplu: <code>/statistici 2024</code>, sau <code>/statistici</code> pentru toți anii."
+
+	searchUsage        = "❌ <b>Format invalid</b>\n\nScrie numărul dosarului fără an, de exemplu <code>/cauta 1234</code>, pentru a-l căuta în toți anii."
+	searchAllYears     = "🔍 Caut numărul <b>%d</b> în documentele din %d ani..."
+	searchTitle        = "🔍 <b>Rezultate pentru numărul %d</b>\n\n"
+	searchNothingFound = "\nNumărul nu apare în niciun document. Te rugăm să verifici numărul."
+	didYouMeanTitle    = "💡 <b>Ai vrut să spui?</b> Același număr apare în alți ani:\n"
+
+	orderUsage    = "❌ <b>Format invalid</b>\n\nScrie numărul ordinului după comandă, de exemplu <code>/ordin 1234/P/2024</code>"
+	orderNotFound = "🔎 Ordinul <code>%s</code> <b>nu apare</b> în niciun document.\n\nTe rugăm să verifici numărul și anul ordinului."
+
+	rangeAdded   = "✅ <b>Abonament adăugat</b>\n\nUrmărești %s. Vei primi un singur mesaj cu toate dosarele rezolvate la fiecare verificare."
+	invalidRange = "❌ <b>Interval invalid</b>\n\n%s\n\nExemple: <code>1000-1500/RD/2022</code> sau <code>*/RD/2022</code> pentru tot anul."
+
+	settingsUsage = "<b>Pentru a modifica:</b>\n" +
+		"• <code>/setari notificari pornit|oprit</code>\n" +
+		"• <code>/setari fus Europe/Bucharest</code>\n" +
+		"• <code>/setari liniste 22-08</code> sau <code>oprit</code>\n" +
+		"• <code>/setari rezumat 9</code> sau <code>oprit</code>\n" +
+		"• <code>/setari consimtamant da|nu</code>"
+	settingsInvalidTimezone = "❌ <b>Fus orar invalid</b>\n\n<code>%s</code> nu este un fus orar cunoscut.\nExemplu: <code>/setari fus Europe/Bucharest</code>"
+
 	helpMessage = "ℹ️ <b>Ajutor și instrucțiuni</b>\n\n" +
 		"📌 <b>Cum verific dosarul?</b>\n" +
 		"Trimite numărul dosarului în formatul: <b>[număr]/RD/[an]</b>\n" +
-		"Exemplu: <code>123/RD/2023</code>\n\n" +
+		"Exemplu: <code>123/RD/2023</code>\n" +
+		"Poți trimite și mai multe dosare într-un singur mesaj, câte unul pe linie.\n\n" +
 		"📌 <b>Ce înseamnă rezultatele?</b>\n" +
 		"✅ <b>Găsit și rezolvat</b> - Dosar finalizat, poți continua procedurile\n" +
 		"🔄 <b>Găsit dar nerezolvat</b> - Dosar în procesare, mai așteaptă\n" +
@@ -46,13 +165,37 @@ Succes în procesul tău! 🍀`
 		"• /start - Pornire bot și mesaj de bun venit\n" +
 		"• /ajutor - Ajutor și informații despre comenzi\n" +
 		"• /abonamente - Listează toate abonamentele tale\n" +
-		"• /adauga [număr]/RD/[an] - Adaugă un abonament la un dosar\n" +
-		"   Exemplu: <code>/adauga 123/RD/2023</code>\n" +
+		"• /adauga [număr]/RD/[an] [etichetă] - Adaugă un abonament la un dosar\n" +
+		"   Exemplu: <code>/adauga 123/RD/2023 Mama</code>\n" +
+		"   Pentru un interval: <code>/adauga 1000-1500/RD/2022</code>, pentru tot anul: <code>/adauga */RD/2022</code>\n" +
 		"• /sterge [număr]/RD/[an] - Șterge un abonament la un dosar\n" +
 		"   Exemplu: <code>/sterge 123/RD/2023</code>\n" +
-		"• /sterge_toate - Șterge toate abonamentele\n\n" +
+		"• /sterge_toate - Șterge toate abonamentele\n" +
+		"• /eticheta [număr]/RD/[an] [etichetă] - Schimbă eticheta unui dosar\n" +
+		"• /nota [număr]/RD/[an] [text] - Adaugă o notiță la un dosar\n" +
+		"• /istoric - Vezi ultimele căutări și verifică-le din nou\n" +
+		"• /cauta [număr] - Caută un număr de dosar în toți anii, dacă nu știi sigur anul\n" +
+		"• /estimare [număr]/RD/[an] - Estimează când va fi rezolvat un dosar în procesare\n" +
+		"• /ordin [număr]/P/[an] - Vezi dosarele rezolvate printr-un ordin și data lui\n" +
+		"• /statistici [an] - Statistici pe an: dosare rezolvate, ultimele ordine și ritmul lor\n" +
+		"• /setari - Vezi și modifică notificările, fusul orar și consimțământul\n" +
+		"• /datele_mele - Descarcă toate datele stocate despre tine\n" +
+		"• /sterge_datele - Șterge definitiv toate datele tale\n\n" +
+		"📌 <b>Import și export</b>\n" +
+		"• Trimite un fișier .csv (dosar, etichetă) sau .json pentru a adăuga multe dosare deodată\n" +
+		"• /export - Descarcă abonamentele și ultima lor stare ca fișier CSV\n" +
+		"• /raport - Raport cu starea, ordinul și vechimea dosarelor, ca fișier HTML și CSV\n\n" +
+		"📌 <b>Din orice conversație</b>\n" +
+		"• Scrie numele botului urmat de numărul dosarului pentru a trimite starea lui în conversație\n\n" +
+		"📌 <b>În grupuri</b>\n" +
+		"• Menționează botul sau răspunde la mesajele lui pentru a verifica un dosar\n" +
+		"• Doar administratorii pot modifica abonamentele grupului\n\n" +
 		"📌 <b>Despre notificări</b>\n" +
 		"• Vei primi notificări când starea dosarului se schimbă\n" +
+		"• Ești anunțat și când dosarul este respins, restituit sau suspendat\n" +
 		"• Poți avea mai multe dosare în abonamente\n" +
-		"• Notificările sunt trimise automat când se detectează schimbări"
+		"• Notificările sunt trimise automat când se detectează schimbări\n" +
+		"• În orele de liniște notificările sunt amânate până la finalul intervalului\n" +
+		"• După rezolvare, dosarul rămâne urmărit până apare programarea la depunerea jurământului\n" +
+		"• Cu rezumatul zilnic primești un singur mesaj pe zi, la ora aleasă"
 )
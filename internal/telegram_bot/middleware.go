@@ -0,0 +1,76 @@
+package telegram_bot
+
+import (
+	"context"
+	"log"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// trackChat records every chat the bot receives updates from, and flags
+// chats where the bot was blocked or removed
+func (h *botHandler) trackChat(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		var err error
+		switch {
+		case update.MyChatMember != nil:
+			member := update.MyChatMember.NewChatMember
+			if member.Type == models.ChatMemberTypeBanned || member.Type == models.ChatMemberTypeLeft {
+				err = h.chatService.MarkBlocked(update.MyChatMember.Chat.ID)
+			} else {
+				err = h.chatService.TouchChat(update.MyChatMember.Chat.ID, string(update.MyChatMember.Chat.Type))
+			}
+		case update.Message != nil && update.Message.MigrateToChatID == 0:
+			err = h.chatService.TouchChat(update.Message.Chat.ID, string(update.Message.Chat.Type))
+		case update.CallbackQuery != nil && update.CallbackQuery.Message.Message != nil:
+			chat := update.CallbackQuery.Message.Message.Chat
+			err = h.chatService.TouchChat(chat.ID, string(chat.Type))
+		}
+		if err != nil {
+			log.Printf("Error tracking chat: %v", err)
+		}
+
+		next(ctx, b, update)
+	}
+}
+
+// trackProfile keeps the profile of the chat up to date with the sender's
+// Telegram details and last activity time
+func (h *botHandler) trackProfile(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		var chat *models.Chat
+		var from *models.User
+		switch {
+		case update.Message != nil && update.Message.MigrateToChatID == 0:
+			chat, from = &update.Message.Chat, update.Message.From
+		case update.CallbackQuery != nil && update.CallbackQuery.Message.Message != nil:
+			chat, from = &update.CallbackQuery.Message.Message.Chat, &update.CallbackQuery.From
+		}
+
+		if chat != nil {
+			if err := h.profileService.TouchProfile(newProfile(chat, from)); err != nil {
+				log.Printf("Error tracking profile: %v", err)
+			}
+		}
+
+		next(ctx, b, update)
+	}
+}
+
+// newProfile builds the Telegram details of a chat. User details are only
+// kept for private chats; groups are described by their title.
+func newProfile(chat *models.Chat, from *models.User) database.Profile {
+	profile := database.Profile{ChatID: chat.ID}
+	if isGroupChat(*chat) || from == nil {
+		profile.Title = chat.Title
+		return profile
+	}
+
+	profile.Username = from.Username
+	profile.FirstName = from.FirstName
+	profile.LastName = from.LastName
+	profile.LanguageCode = from.LanguageCode
+	return profile
+}
@@ -0,0 +1,69 @@
+package telegram_bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	cmdOrder = "ordin"
+	// maxListedOrderDossiers limits the dossiers listed for an order
+	maxListedOrderDossiers = 50
+)
+
+// orderCommand lists the dossiers resolved by an order and when it was published
+func (b *botService) orderCommand(ctx context.Context, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	_, args, _ := strings.Cut(update.Message.Text, " ")
+	number, err := decree.ParseOrderNumber(args)
+	if err != nil {
+		b.bh.SendMessage(ctx, chatID, orderUsage)
+		return
+	}
+
+	var years []int
+	for _, source := range b.processor.Sources() {
+		years = append(years, source.Year)
+	}
+	searchCtx, cancel := context.WithTimeout(ctx, decree.SearchBudget)
+	defer cancel()
+	order, found, err := b.processor.FindOrder(searchCtx, number, years)
+	if err != nil {
+		b.bh.SendMessage(ctx, chatID, fmt.Sprintf(errorMessage, html.EscapeString(err.Error())))
+		return
+	}
+	if !found {
+		b.bh.SendMessage(ctx, chatID, fmt.Sprintf(orderNotFound, number))
+		return
+	}
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("📜 <b>Ordinul %s</b>\n\n", order.Number))
+	if !order.Date.IsZero() {
+		response.WriteString(fmt.Sprintf("📅 Data ordinului: <b>%s</b>\n", order.Date.Format("02.01.2006")))
+	}
+	firstSeen, err := b.revisionService.GetOrderFirstSeen(order.Number)
+	if err != nil {
+		fmt.Printf("Error getting first revision of order: %v\n", err)
+	}
+	if firstSeen != nil {
+		response.WriteString(fmt.Sprintf("🆕 Apărut în documente: <b>%s</b>\n", firstSeen.Format("02.01.2006")))
+	}
+	response.WriteString(fmt.Sprintf("✅ Dosare rezolvate: <b>%d</b>\n\n<pre>", len(order.Dossiers)))
+	for i, entry := range order.Dossiers {
+		if i == maxListedOrderDossiers {
+			response.WriteString(fmt.Sprintf("… și încă %d\n", len(order.Dossiers)-maxListedOrderDossiers))
+			break
+		}
+		response.WriteString(entry.Number.String() + "\n")
+	}
+	response.WriteString("</pre>")
+
+	b.bh.SendMessage(ctx, chatID, response.String())
+}
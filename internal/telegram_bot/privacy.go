@@ -0,0 +1,90 @@
+package telegram_bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	cmdExportData = "datele_mele"
+	cmdEraseData  = "sterge_datele"
+
+	// eraseConfirmation confirms an erasure in groups: Button.OnSelect only
+	// receives the chat, so a tap there cannot be checked against admin rights
+	eraseConfirmation = "confirm"
+)
+
+// exportDataCommand sends everything stored about the chat as a JSON document
+func (h *botHandler) exportDataCommand(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	if !h.canManageSubscriptions(ctx, update.Message) {
+		h.SendMessage(ctx, chatID, groupAdminOnly)
+		return
+	}
+
+	data, err := h.privacyService.ExportChatData(chatID)
+	if err != nil {
+		log.Printf("Error exporting data for chat %d: %v", chatID, err)
+		h.SendMessage(ctx, chatID, "❌ <b>Eroare la exportul datelor</b>\n\nTe rugăm să încerci din nou mai târziu.")
+		return
+	}
+
+	document, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		log.Printf("Error encoding data for chat %d: %v", chatID, err)
+		h.SendMessage(ctx, chatID, "❌ <b>Eroare la exportul datelor</b>\n\nTe rugăm să încerci din nou mai târziu.")
+		return
+	}
+
+	filename := fmt.Sprintf("datele_mele_%s.json", time.Now().Format("2006-01-02"))
+	if err := h.SendDocument(ctx, chatID, filename, document, exportDataCaption); err != nil {
+		log.Printf("Error sending data export to chat %d: %v", chatID, err)
+	}
+}
+
+// eraseDataCommand asks for confirmation, then erases every row stored about the chat
+func (h *botHandler) eraseDataCommand(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	if !h.canManageSubscriptions(ctx, update.Message) {
+		h.SendMessage(ctx, chatID, groupAdminOnly)
+		return
+	}
+
+	if isGroupChat(update.Message.Chat) {
+		parts := strings.Fields(update.Message.Text)
+		if len(parts) > 1 && strings.EqualFold(parts[1], eraseConfirmation) {
+			h.eraseData(ctx, chatID)
+			return
+		}
+		h.SendMessage(ctx, chatID, fmt.Sprintf(eraseDataWarning+eraseDataGroupConfirm, eraseConfirmation))
+		return
+	}
+
+	err := h.SendMessageWithButtons(ctx, chatID, eraseDataWarning,
+		Button{Text: "🧹 Da, șterge tot", OnSelect: h.eraseData},
+		Button{Text: "❌ Anulează", OnSelect: func(ctx context.Context, chatID int64) {
+			h.SendMessage(ctx, chatID, "👌 Datele tale au rămas neschimbate.")
+		}},
+	)
+	if err != nil {
+		log.Printf("Error sending erase confirmation: %v", err)
+	}
+}
+
+func (h *botHandler) eraseData(ctx context.Context, chatID int64) {
+	if err := h.privacyService.EraseChatData(chatID); err != nil {
+		log.Printf("Error erasing data for chat %d: %v", chatID, err)
+		h.SendMessage(ctx, chatID, "❌ <b>Eroare la ștergerea datelor</b>\n\nTe rugăm să încerci din nou mai târziu.")
+		return
+	}
+
+	log.Printf("Erased all data for chat %d", chatID)
+	h.SendMessage(ctx, chatID, eraseDataDone)
+}
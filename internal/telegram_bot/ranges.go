@@ -0,0 +1,65 @@
+package telegram_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+)
+
+// newRangeSubscription builds the subscription watching a range or a whole year
+func newRangeSubscription(chatID int64, r dossier.Range, label string) database.Subscription {
+	kind := database.SubscriptionRange
+	if r.Whole() {
+		kind = database.SubscriptionYear
+	}
+	return database.Subscription{
+		ChatID:       chatID,
+		DecreeNumber: r.String(),
+		Label:        label,
+		Kind:         kind,
+		RangeYear:    r.Year,
+		RangeFrom:    r.From,
+		RangeTo:      r.To,
+	}
+}
+
+// addRangeSubscription handles "/adauga 1000-1500/RD/2022" and "/adauga */RD/2022"
+func (h *botHandler) addRangeSubscription(ctx context.Context, chatID int64, r dossier.Range, label string) {
+	subscription := newRangeSubscription(chatID, r, label)
+
+	err := h.subscriptionService.CreateRangeSubscription(subscription)
+	if err != nil {
+		if strings.Contains(err.Error(), "subscription already exists") {
+			if label != "" {
+				h.updateLabel(ctx, chatID, subscription.DecreeNumber, label)
+				return
+			}
+			h.SendMessage(ctx, chatID, fmt.Sprintf("ℹ️ <b>Abonament existent</b>\n\nEști deja abonat la <code>%s</code>", subscription.DecreeNumber))
+			return
+		}
+		h.SendMessage(ctx, chatID, "❌ <b>Eroare la adăugarea abonamentului</b>\n\nTe rugăm să încerci din nou mai târziu.")
+		return
+	}
+
+	h.SendMessage(ctx, chatID, fmt.Sprintf(rangeAdded, formatSubscription(subscription)))
+}
+
+// parseRangeArgument parses a range at the start of args. It reports false
+// when args is not a range, after telling the user about a malformed one.
+func (h *botHandler) parseRangeArgument(ctx context.Context, chatID int64, args string) (dossier.Range, string, bool, bool) {
+	r, rest, err := dossier.ParseRange(args)
+	switch {
+	case err == nil:
+		return r, rest, true, true
+	case errors.Is(err, dossier.ErrNotRange):
+		return dossier.Range{}, "", false, true
+	default:
+		h.SendMessage(ctx, chatID, fmt.Sprintf(invalidRange, html.EscapeString(err.Error())))
+		return dossier.Range{}, "", false, false
+	}
+}
@@ -0,0 +1,78 @@
+package telegram_bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/bulk"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/go-telegram/bot/models"
+)
+
+const cmdReport = "raport"
+
+// reportCommand sends a status report for the dossiers listed after the
+// command, or for the chat's subscriptions, as CSV and HTML documents
+func (b *botService) reportCommand(ctx context.Context, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	var searches []string
+	for _, match := range dossier.Extract(update.Message.Text) {
+		searches = append(searches, match.Canonical())
+	}
+	listed := len(searches) > 0
+
+	// Labels name the clients in the report, whether or not the dossiers were listed explicitly
+	subscriptions, err := b.subscriptionService.GetSubscriptions(chatID)
+	if err != nil {
+		fmt.Printf("Error getting subscriptions for report: %v\n", err)
+	}
+	labels := make(map[string]string, len(subscriptions))
+	for _, subscription := range subscriptions {
+		labels[subscription.DecreeNumber] = subscription.Label
+		if !listed && !subscription.IsRange() {
+			searches = append(searches, subscription.DecreeNumber)
+		}
+	}
+
+	if len(searches) == 0 {
+		b.bh.SendMessage(ctx, chatID, reportUsage)
+		return
+	}
+	if len(searches) > bulk.MaxRows {
+		b.bh.SendMessage(ctx, chatID, fmt.Sprintf(reportTooMany, bulk.MaxRows, len(searches)))
+		return
+	}
+
+	b.bh.SendMessage(ctx, chatID, fmt.Sprintf(reportGenerating, len(searches)))
+
+	report, err := b.processor.Report(searches)
+	if err != nil {
+		b.bh.SendMessage(ctx, chatID, fmt.Sprintf(errorMessage, err.Error()))
+		return
+	}
+	report.Labels = labels
+
+	csvDocument, err := report.CSV()
+	if err != nil {
+		b.bh.SendMessage(ctx, chatID, fmt.Sprintf(errorMessage, err.Error()))
+		return
+	}
+	htmlDocument, err := report.HTML()
+	if err != nil {
+		b.bh.SendMessage(ctx, chatID, fmt.Sprintf(errorMessage, err.Error()))
+		return
+	}
+
+	resolved, pending, closed, notFound, failed := report.Summary()
+	date := time.Now().Format("2006-01-02")
+	caption := fmt.Sprintf(reportCaption, len(report.Rows), resolved, pending, closed, notFound, failed)
+	if err := b.bh.SendDocument(ctx, chatID, fmt.Sprintf("raport_%s.html", date), htmlDocument, caption); err != nil {
+		fmt.Printf("Error sending HTML report: %v\n", err)
+		return
+	}
+	if err := b.bh.SendDocument(ctx, chatID, fmt.Sprintf("raport_%s.csv", date), csvDocument, ""); err != nil {
+		fmt.Printf("Error sending CSV report: %v\n", err)
+	}
+}
@@ -0,0 +1,122 @@
+package telegram_bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	// maxListedRevisions is the number of revisions shown by /admin_revisions
+	maxListedRevisions = 15
+	// maxListedChanges limits the dossiers listed per kind of change
+	maxListedChanges = 30
+)
+
+// revisionChangeTitles names the kinds of RevisionChange, in display order
+var revisionChangeTitles = []struct {
+	kind  string
+	title string
+}{
+	{database.ChangeResolved, "✅ Rezolvate"},
+	{database.ChangeAdded, "🆕 Adăugate"},
+	{database.ChangeRemoved, "➖ Eliminate"},
+	{database.ChangeChanged, "✏️ Modificate"},
+}
+
+// adminRevisionsCommand lists the recorded PDF revisions, or the changes of
+// one revision when its ID is given
+func (b *botService) adminRevisionsCommand(ctx context.Context, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	parts := strings.Fields(update.Message.Text)
+	if len(parts) > 1 {
+		id, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			b.sendAdminMessage(ctx, chatID, "❌ <b>ID de revizie invalid</b>\n\nExemplu: <code>/admin_revisions 12</code>")
+			return
+		}
+		b.sendRevisionChanges(ctx, chatID, uint(id))
+		return
+	}
+
+	revisions, err := b.revisionService.GetRecentRevisions(maxListedRevisions)
+	if err != nil {
+		b.sendAdminError(ctx, chatID, err)
+		return
+	}
+	if len(revisions) == 0 {
+		b.sendAdminMessage(ctx, chatID, "🆕 <b>Nicio revizie înregistrată</b>\n\nReviziile sunt înregistrate la verificarea abonamentelor.")
+		return
+	}
+
+	var response strings.Builder
+	response.WriteString("🆕 <b>Revizii PDF</b>\n\n")
+	for _, revision := range revisions {
+		response.WriteString(fmt.Sprintf("<b>#%d</b> · %d · <code>%s</code> · %s\n", revision.ID, revision.Year,
+			revision.Hash[:min(12, len(revision.Hash))], revision.CreatedAt.Format("02.01.2006 15:04")))
+		if revision.Baseline() {
+			response.WriteString(fmt.Sprintf("   prima revizie, %d dosare\n", revision.Entries))
+			continue
+		}
+		response.WriteString(fmt.Sprintf("   ✅ %d rezolvate · 🆕 %d adăugate · ➖ %d eliminate · ✏️ %d modificate\n",
+			revision.NewlyResolved, revision.Added, revision.Removed, revision.Changed))
+	}
+	response.WriteString("\nFolosește <code>/admin_revisions ID</code> pentru a vedea dosarele schimbate.")
+
+	b.sendAdminMessage(ctx, chatID, response.String())
+}
+
+func (b *botService) sendRevisionChanges(ctx context.Context, chatID int64, id uint) {
+	revision, err := b.revisionService.GetRevision(id)
+	if err != nil {
+		b.sendAdminError(ctx, chatID, err)
+		return
+	}
+	changes, err := b.revisionService.GetChanges(id)
+	if err != nil {
+		b.sendAdminError(ctx, chatID, err)
+		return
+	}
+
+	byKind := make(map[string][]database.RevisionChange)
+	for _, change := range changes {
+		byKind[change.Kind] = append(byKind[change.Kind], change)
+	}
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("🆕 <b>Revizia #%d</b> (%d), %s\n", revision.ID, revision.Year, revision.CreatedAt.Format("02.01.2006 15:04")))
+	if revision.Baseline() {
+		response.WriteString(fmt.Sprintf("\nPrima revizie înregistrată, %d dosare.", revision.Entries))
+		b.sendAdminMessage(ctx, chatID, response.String())
+		return
+	}
+
+	for _, kind := range revisionChangeTitles {
+		listed := byKind[kind.kind]
+		if len(listed) == 0 {
+			continue
+		}
+		response.WriteString(fmt.Sprintf("\n<b>%s: %d</b>\n", kind.title, len(listed)))
+		for i, change := range listed {
+			if i == maxListedChanges {
+				response.WriteString(fmt.Sprintf("… și încă %d\n", len(listed)-maxListedChanges))
+				break
+			}
+			response.WriteString("• <code>" + change.DecreeNumber + "</code>")
+			if change.Order != "" {
+				response.WriteString(" — " + change.Order)
+			}
+			response.WriteString("\n")
+		}
+	}
+	if len(changes) == 0 {
+		response.WriteString("\nNiciun dosar nu s-a schimbat.")
+	}
+
+	b.sendAdminMessage(ctx, chatID, response.String())
+}
@@ -0,0 +1,141 @@
+package telegram_bot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	cmdSearch = "cauta"
+	// adjacentYears is how many years before and after a not found dossier's
+	// year are searched for the same number
+	adjacentYears = 2
+	// suggestionBudget bounds the search for suggestions: they follow the
+	// not found reply, so only documents that load quickly are worth waiting for
+	suggestionBudget = 5 * time.Second
+)
+
+// searchCommand looks up a dossier number in the documents of every supported year
+func (b *botService) searchCommand(ctx context.Context, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	parts := strings.Fields(update.Message.Text)
+	if len(parts) < 2 {
+		b.bh.SendMessage(ctx, chatID, searchUsage)
+		return
+	}
+	value, err := strconv.Atoi(strings.TrimLeft(parts[1], "0"))
+	if err != nil || value < 1 || value > dossier.MaxNumber {
+		b.bh.SendMessage(ctx, chatID, searchUsage)
+		return
+	}
+
+	var years []int
+	for _, source := range b.processor.Sources() {
+		years = append(years, source.Year)
+	}
+
+	b.bh.SendMessage(ctx, chatID, fmt.Sprintf(searchAllYears, value, len(years)))
+	results := b.searchYears(ctx, value, years, decree.SearchBudget)
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf(searchTitle, value))
+	response.WriteString("<pre>")
+	var found []decree.Result
+	for _, result := range results {
+		status := "⚠️ indisponibil"
+		if result.Err == nil {
+			status = stateLabel(result.State)
+			if result.State != decree.StateNotFound {
+				found = append(found, result)
+			}
+		}
+		response.WriteString(fmt.Sprintf("%-13s %s\n", result.DecreeNumber, status))
+	}
+	response.WriteString("</pre>")
+	if len(found) == 0 {
+		response.WriteString(searchNothingFound)
+	}
+
+	if err := b.bh.SendMessageWithButtonRows(ctx, chatID, response.String(), b.recheckButtons(found)); err != nil {
+		fmt.Printf("Error sending search results: %v\n", err)
+	}
+}
+
+// sendWithSuggestions sends the not found response of a lookup right away,
+// then suggests the adjacent years whose documents list the same number
+func (b *botService) sendWithSuggestions(ctx context.Context, chatID int64, response string, number dossier.Number) {
+	if err := b.bh.SendMessage(ctx, chatID, response); err != nil {
+		fmt.Printf("Error sending response message: %v\n", err)
+		return
+	}
+
+	var years []int
+	for _, source := range b.processor.Sources() {
+		if source.Year != number.Year && abs(source.Year-number.Year) <= adjacentYears {
+			years = append(years, source.Year)
+		}
+	}
+	// The closest years are the likeliest mistakes
+	sort.Slice(years, func(i, j int) bool {
+		return abs(years[i]-number.Year) < abs(years[j]-number.Year)
+	})
+
+	var found []decree.Result
+	for _, result := range b.searchYears(ctx, number.Value, years, suggestionBudget) {
+		if result.Err == nil && result.State != decree.StateNotFound {
+			found = append(found, result)
+		}
+	}
+	if len(found) == 0 {
+		return
+	}
+
+	var suggestions strings.Builder
+	suggestions.WriteString(didYouMeanTitle)
+	for _, result := range found {
+		suggestions.WriteString(fmt.Sprintf("• <code>%s</code> — %s\n", result.DecreeNumber, stateLabel(result.State)))
+	}
+	if err := b.bh.SendMessageWithButtonRows(ctx, chatID, suggestions.String(), b.recheckButtons(found)); err != nil {
+		fmt.Printf("Error sending suggestions: %v\n", err)
+	}
+}
+
+func (b *botService) searchYears(ctx context.Context, value int, years []int, budget time.Duration) []decree.Result {
+	if len(years) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, budget)
+	defer cancel()
+	return b.processor.Search(ctx, value, years)
+}
+
+// recheckButtons offers a full lookup of each result, one button per row
+func (b *botService) recheckButtons(results []decree.Result) [][]Button {
+	rows := make([][]Button, 0, len(results))
+	for _, result := range results {
+		match, err := dossier.Parse(result.DecreeNumber)
+		if err != nil {
+			continue
+		}
+		rows = append(rows, []Button{{Text: "🔄 Verifică " + result.DecreeNumber, OnSelect: func(ctx context.Context, chatID int64) {
+			b.handleDecreeRequest(ctx, chatID, match)
+		}}})
+	}
+	return rows
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -3,11 +3,16 @@ package telegram_bot
 import (
 	"context"
 	"fmt"
-	"regexp"
+	"html"
+	"strconv"
 	"strings"
 
+	"github.com/andiq123/cetatenie-analyzer/internal/analytics"
+	"github.com/andiq123/cetatenie-analyzer/internal/archive"
+	"github.com/andiq123/cetatenie-analyzer/internal/cache"
 	"github.com/andiq123/cetatenie-analyzer/internal/database"
 	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
 	"github.com/andiq123/cetatenie-analyzer/internal/timer"
 	"github.com/go-telegram/bot/models"
 	"gorm.io/gorm"
@@ -16,27 +21,73 @@ import (
 const (
 	errorSendingMessage   = "error sending message: %w"
 	errorProcessingDecree = "error processing decree: %w"
+	// maxDecreesPerMessage limits how many dossiers a single message may check
+	maxDecreesPerMessage = 50
 )
 
 type BotService interface {
 	Start(ctx context.Context) error
 	SendMessage(ctx context.Context, chatID int64, text string) error
+	SendDocument(ctx context.Context, chatID int64, filename string, data []byte, caption string) error
+	SetChecker(checker SubscriptionChecker)
 }
 
 type botService struct {
-	bh        TelegramBot
-	processor decree.Processor
+	bh                  TelegramBot
+	processor           decree.Processor
+	subscriptionService database.SubscriptionService
+	chatService         database.ChatService
+	broadcastService    database.BroadcastService
+	checker             SubscriptionChecker
+	inlineCache         *cache.Cache
+	inlineLookups       *inlineLookups
+	admins              map[int64]bool
+	lookupService       database.LookupService
+	profileService      database.ProfileService
+	revisionService     database.RevisionService
+	archive             archive.Service
+	statsCache          *analytics.SummaryCache
+	broadcasts          *broadcastRuns
 }
 
-func NewBot(db *gorm.DB) BotService {
+func NewBot(db *gorm.DB, processor decree.Processor, archive archive.Service) BotService {
+	subscriptionService := database.NewSubscriptionService(db)
+	chatService := database.NewChatService(db)
+	profileService := database.NewProfileService(db)
 	return &botService{
-		processor: decree.NewProcessor(),
-		bh:        NewBotHandler(database.NewSubscriptionService(db)),
+		processor:           processor,
+		bh:                  NewBotHandler(subscriptionService, chatService, profileService, database.NewPrivacyService(db)),
+		subscriptionService: subscriptionService,
+		chatService:         chatService,
+		broadcastService:    database.NewBroadcastService(db),
+		inlineCache:         cache.New(inlineStateTTL),
+		inlineLookups:       &inlineLookups{pending: make(map[string]chan struct{})},
+		admins:              loadAdminChatIDs(),
+		lookupService:       database.NewLookupService(db),
+		profileService:      profileService,
+		revisionService:     database.NewRevisionService(db),
+		archive:             archive,
+		statsCache:          analytics.NewSummaryCache(),
+		broadcasts:          &broadcastRuns{cancels: make(map[uint]context.CancelFunc)},
 	}
 }
 
+// SetChecker sets the subscription checker triggered by /admin_check_now
+func (b *botService) SetChecker(checker SubscriptionChecker) {
+	b.checker = checker
+}
+
 func (b *botService) Start(ctx context.Context) error {
-	if err := b.bh.Init(b.defaultHandler, ctx); err != nil {
+	b.registerAdminCommands()
+	b.bh.HandleCommand(cmdHistory, b.historyCommand)
+	b.bh.HandleCommand(cmdReport, b.reportCommand)
+	b.bh.HandleCommand(cmdEstimate, b.estimateCommand)
+	b.bh.HandleCommand(cmdStatistics, b.statisticsCommand)
+	b.bh.HandleCommand(cmdSearch, b.searchCommand)
+	b.bh.HandleCommand(cmdOrder, b.orderCommand)
+	b.bh.OnStart(b.resumeBroadcasts)
+
+	if err := b.bh.Init(b.defaultHandler, b.handleInlineQuery, ctx); err != nil {
 		return fmt.Errorf("failed to initialize bot: %w", err)
 	}
 	return nil
@@ -49,22 +100,34 @@ func (b *botService) SendMessage(ctx context.Context, chatID int64, text string)
 	return nil
 }
 
+func (b *botService) SendDocument(ctx context.Context, chatID int64, filename string, data []byte, caption string) error {
+	if err := b.bh.SendDocument(ctx, chatID, filename, data, caption); err != nil {
+		return fmt.Errorf(errorSendingMessage, err)
+	}
+	return nil
+}
+
 func (b *botService) defaultHandler(ctx context.Context, update *models.Update) {
-	if !regexp.MustCompile(decreePattern).MatchString(update.Message.Text) {
+	matches := dossier.Extract(update.Message.Text)
+	if len(matches) == 0 {
 		if err := b.bh.SendMessage(ctx, update.Message.Chat.ID, invalidFormat); err != nil {
 			fmt.Printf("Error sending invalid format message: %v\n", err)
 		}
 		return
 	}
 
-	b.handleDecreeRequest(ctx, update)
+	if len(matches) > 1 {
+		b.handleMultiDecreeRequest(ctx, update.Message.Chat.ID, matches)
+		return
+	}
+
+	b.handleDecreeRequest(ctx, update.Message.Chat.ID, matches[0])
 }
 
-func (b *botService) handleDecreeRequest(ctx context.Context, update *models.Update) {
-	senderId := update.Message.Chat.ID
-	decreeNumber := strings.TrimSpace(update.Message.Text)
+func (b *botService) handleDecreeRequest(ctx context.Context, senderId int64, match dossier.Match) {
+	decreeNumber := match.Canonical()
 
-	if err := b.bh.SendMessage(ctx, senderId, fmt.Sprintf(searching, decreeNumber)); err != nil {
+	if err := b.bh.SendMessage(ctx, senderId, formatCorrections(match)+fmt.Sprintf(searching, decreeNumber)); err != nil {
 		fmt.Printf("Error sending searching message: %v\n", err)
 		return
 	}
@@ -76,20 +139,34 @@ func (b *botService) handleDecreeRequest(ctx context.Context, update *models.Upd
 		}
 		return
 	}
+	b.recordLookups(senderId, []decree.Result{{DecreeNumber: decreeNumber, State: findState}})
+	b.inlineCache.Set(decreeNumber, []byte(strconv.Itoa(int(findState))))
 
 	var response string
 	switch findState {
 	case decree.StateFoundAndResolved:
 		response = fmt.Sprintf(successMessage, decreeNumber, timer.FormatDuration(timeReport.FetchTime), timer.FormatDuration(timeReport.ParseTime))
 	case decree.StateFoundButNotResolved:
-		response = fmt.Sprintf(inProgressMsg, decreeNumber, timer.FormatDuration(timeReport.FetchTime), timer.FormatDuration(timeReport.ParseTime))
+		response = fmt.Sprintf(inProgressMsg, decreeNumber, timer.FormatDuration(timeReport.FetchTime), timer.FormatDuration(timeReport.ParseTime), b.queuePosition(match.Number))
 		if err := b.bh.SendMessageWithSubscribe(ctx, senderId, response, decreeNumber); err != nil {
 			fmt.Printf("Error sending message with subscribe: %v\n", err)
 			return
 		}
 		return
+	case decree.StateRejected:
+		response = fmt.Sprintf(rejectedMsg, decreeNumber, timer.FormatDuration(timeReport.FetchTime), timer.FormatDuration(timeReport.ParseTime), b.solution(match.Number))
+	case decree.StateReturned:
+		response = fmt.Sprintf(returnedMsg, decreeNumber, timer.FormatDuration(timeReport.FetchTime), timer.FormatDuration(timeReport.ParseTime), b.solution(match.Number))
+	case decree.StateSuspended:
+		response = fmt.Sprintf(suspendedMsg, decreeNumber, timer.FormatDuration(timeReport.FetchTime), timer.FormatDuration(timeReport.ParseTime), b.solution(match.Number))
+		if err := b.bh.SendMessageWithSubscribe(ctx, senderId, response, decreeNumber); err != nil {
+			fmt.Printf("Error sending message with subscribe: %v\n", err)
+		}
+		return
 	case decree.StateNotFound:
 		response = fmt.Sprintf(notFoundMsg, decreeNumber, timer.FormatDuration(timeReport.FetchTime), timer.FormatDuration(timeReport.ParseTime))
+		b.sendWithSuggestions(ctx, senderId, response, match.Number)
+		return
 	default:
 		response = unknownState
 	}
@@ -98,3 +175,114 @@ func (b *botService) handleDecreeRequest(ctx context.Context, update *models.Upd
 		fmt.Printf("Error sending response message: %v\n", err)
 	}
 }
+
+// queuePosition describes where a pending dossier stands in its year, or
+// nothing if the year's document cannot be loaded
+func (b *botService) queuePosition(number dossier.Number) string {
+	doc, err := b.processor.Document(number.Year)
+	if err != nil {
+		fmt.Printf("Error loading document for queue position: %v\n", err)
+		return ""
+	}
+
+	position := analytics.QueuePosition(doc, number)
+	highest := "niciunul încă"
+	if position.HighestResolved > 0 {
+		highest = dossier.Number{Value: position.HighestResolved, Year: number.Year}.String()
+	}
+	text := fmt.Sprintf(queuePositionMsg, number.Year, position.PendingBefore, highest,
+		position.PercentResolved(), position.Resolved, position.Total)
+	if position.Overtaken() {
+		text += queueOvertakenMsg
+	}
+	return text + "\n"
+}
+
+// solution shows the solution column of a dossier's row, which explains a
+// rejection, return or suspension, or nothing if it cannot be loaded
+func (b *botService) solution(number dossier.Number) string {
+	doc, err := b.processor.Document(number.Year)
+	if err != nil {
+		fmt.Printf("Error loading document for solution: %v\n", err)
+		return ""
+	}
+	entry, ok := doc.Lookup(number)
+	if !ok || entry.Solution == "" {
+		return ""
+	}
+	return fmt.Sprintf(solutionLine, html.EscapeString(entry.Solution))
+}
+
+func (b *botService) handleMultiDecreeRequest(ctx context.Context, senderId int64, matches []dossier.Match) {
+	if len(matches) > maxDecreesPerMessage {
+		if err := b.bh.SendMessage(ctx, senderId, fmt.Sprintf(tooManyDecrees, maxDecreesPerMessage, len(matches))); err != nil {
+			fmt.Printf("Error sending too many decrees message: %v\n", err)
+		}
+		return
+	}
+
+	if err := b.bh.SendMessage(ctx, senderId, fmt.Sprintf(multiSearching, len(matches))); err != nil {
+		fmt.Printf("Error sending searching message: %v\n", err)
+		return
+	}
+
+	searches := make([]string, len(matches))
+	for i, match := range matches {
+		searches[i] = match.Canonical()
+	}
+
+	results, timeReport, err := b.processor.HandleMany(searches)
+	if err != nil {
+		if err := b.bh.SendMessage(ctx, senderId, fmt.Sprintf(errorMessage, err.Error())); err != nil {
+			fmt.Printf("Error sending error message: %v\n", err)
+		}
+		return
+	}
+	b.recordLookups(senderId, results)
+
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf(multiResultTitle, len(results)))
+	for _, match := range matches {
+		if match.Corrected() {
+			response.WriteString(fmt.Sprintf(correctionLine, match.Raw, match.Canonical()))
+		}
+	}
+
+	var resolved, pending, closed, notFound, failed int
+	var pendingNumbers []string
+	response.WriteString("<pre>")
+	for _, result := range results {
+		status := "⚠️ eroare"
+		switch {
+		case result.Err != nil:
+			failed++
+		case result.State == decree.StateFoundAndResolved:
+			resolved++
+		case result.State.Pending():
+			pending++
+			pendingNumbers = append(pendingNumbers, result.DecreeNumber)
+		case result.State.Closed():
+			closed++
+		default:
+			notFound++
+		}
+		if result.Err == nil {
+			status = stateLabel(result.State)
+		}
+		response.WriteString(fmt.Sprintf("%-13s %s\n", result.DecreeNumber, status))
+	}
+	response.WriteString("</pre>")
+	response.WriteString(fmt.Sprintf(multiSummary, resolved, pending, closed, notFound, failed,
+		timer.FormatDuration(timeReport.FetchTime), timer.FormatDuration(timeReport.ParseTime)))
+
+	if len(pendingNumbers) > 0 {
+		if err := b.bh.SendMessageWithSubscribeAll(ctx, senderId, response.String(), pendingNumbers); err != nil {
+			fmt.Printf("Error sending message with subscribe all: %v\n", err)
+		}
+		return
+	}
+
+	if err := b.bh.SendMessage(ctx, senderId, response.String()); err != nil {
+		fmt.Printf("Error sending response message: %v\n", err)
+	}
+}
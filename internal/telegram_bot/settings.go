@@ -0,0 +1,275 @@
+package telegram_bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/database"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const cmdSettings = "setari"
+
+// errInvalidSetting reports arguments that do not match the /setari syntax
+var errInvalidSetting = errors.New("setare invalidă")
+
+// settingsTimezones are offered as buttons; any IANA timezone can be set with /setari fus
+var settingsTimezones = []string{
+	"Europe/Bucharest", "Europe/Chisinau", "Europe/London", "Europe/Berlin",
+	"Europe/Rome", "Europe/Madrid", "America/New_York", "Asia/Jerusalem",
+}
+
+// settingsCommand shows the chat's preferences or changes one of them:
+// /setari notificari pornit|oprit, /setari fus <fus orar>, /setari consimtamant da|nu,
+// /setari liniste <start>-<sfârșit>|pornit|oprit, /setari rezumat <oră>|pornit|oprit
+func (h *botHandler) settingsCommand(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	parts := strings.Fields(update.Message.Text)
+	if len(parts) < 2 {
+		h.sendSettings(ctx, chatID)
+		return
+	}
+
+	if !h.canManageSubscriptions(ctx, update.Message) {
+		h.SendMessage(ctx, chatID, groupAdminOnly)
+		return
+	}
+
+	if len(parts) < 3 {
+		h.SendMessage(ctx, chatID, settingsUsage)
+		return
+	}
+
+	var err error
+	switch strings.ToLower(parts[1]) {
+	case "notificari":
+		enabled, ok := parseToggle(parts[2])
+		if !ok {
+			h.SendMessage(ctx, chatID, settingsUsage)
+			return
+		}
+		err = h.profileService.SetNotifications(chatID, enabled)
+	case "fus":
+		err = h.profileService.SetTimezone(chatID, parts[2])
+		if err != nil {
+			h.SendMessage(ctx, chatID, fmt.Sprintf(settingsInvalidTimezone, html.EscapeString(parts[2])))
+			return
+		}
+	case "liniste":
+		err = h.setQuietHours(chatID, parts[2])
+		if errors.Is(err, errInvalidSetting) {
+			h.SendMessage(ctx, chatID, settingsUsage)
+			return
+		}
+	case "rezumat":
+		err = h.setDigest(chatID, parts[2])
+		if errors.Is(err, errInvalidSetting) {
+			h.SendMessage(ctx, chatID, settingsUsage)
+			return
+		}
+	case "consimtamant":
+		given, ok := parseToggle(parts[2])
+		if !ok {
+			h.SendMessage(ctx, chatID, settingsUsage)
+			return
+		}
+		err = h.profileService.SetConsent(chatID, given)
+	default:
+		h.SendMessage(ctx, chatID, settingsUsage)
+		return
+	}
+
+	if err != nil {
+		log.Printf("Error updating settings for chat %d: %v", chatID, err)
+		h.SendMessage(ctx, chatID, "❌ <b>Eroare la salvarea setărilor</b>\n\nTe rugăm să încerci din nou mai târziu.")
+		return
+	}
+	h.sendSettings(ctx, chatID)
+}
+
+// sendSettings shows the current preferences. Private chats get buttons to
+// change them; Button.OnSelect only receives the chat, so a tap in a group
+// cannot be checked against admin rights and admins use the text commands.
+func (h *botHandler) sendSettings(ctx context.Context, chatID int64) {
+	profile, err := h.profileService.GetProfile(chatID)
+	if err != nil {
+		log.Printf("Error getting profile for chat %d: %v", chatID, err)
+		h.SendMessage(ctx, chatID, "❌ <b>Eroare la obținerea setărilor</b>\n\nTe rugăm să încerci din nou mai târziu.")
+		return
+	}
+
+	text := formatSettings(profile)
+	if isGroupChatID(chatID) {
+		h.SendMessage(ctx, chatID, text)
+		return
+	}
+
+	notificationsLabel := "🔕 Oprește notificările"
+	if !profile.NotificationsEnabled {
+		notificationsLabel = "🔔 Pornește notificările"
+	}
+	quietLabel := "🌙 Pornește orele de liniște"
+	if profile.QuietHoursEnabled {
+		quietLabel = "🌙 Oprește orele de liniște"
+	}
+	digestLabel := "📬 Primește un rezumat zilnic"
+	if profile.DigestEnabled {
+		digestLabel = "📬 Primește notificările imediat"
+	}
+	consentLabel := "🛡 Acordă consimțământul"
+	if profile.ConsentGiven {
+		consentLabel = "🚫 Retrage consimțământul"
+	}
+
+	err = h.SendMessageWithButtonRows(ctx, chatID, text, [][]Button{
+		{{Text: notificationsLabel, OnSelect: func(ctx context.Context, chatID int64) {
+			h.applySetting(ctx, chatID, h.profileService.SetNotifications(chatID, !profile.NotificationsEnabled))
+		}}},
+		{{Text: quietLabel, OnSelect: func(ctx context.Context, chatID int64) {
+			h.applySetting(ctx, chatID, h.profileService.SetQuietHours(chatID, !profile.QuietHoursEnabled, profile.QuietStart, profile.QuietEnd))
+		}}},
+		{{Text: digestLabel, OnSelect: func(ctx context.Context, chatID int64) {
+			h.applySetting(ctx, chatID, h.profileService.SetDigest(chatID, !profile.DigestEnabled, profile.DigestHour))
+		}}},
+		{{Text: consentLabel, OnSelect: func(ctx context.Context, chatID int64) {
+			h.applySetting(ctx, chatID, h.profileService.SetConsent(chatID, !profile.ConsentGiven))
+		}}},
+		{{Text: "🌍 Schimbă fusul orar", OnSelect: h.sendTimezoneChoices}},
+	})
+	if err != nil {
+		log.Printf("Error sending settings: %v", err)
+	}
+}
+
+func (h *botHandler) sendTimezoneChoices(ctx context.Context, chatID int64) {
+	rows := make([][]Button, 0, (len(settingsTimezones)+1)/2)
+	for i, timezone := range settingsTimezones {
+		timezone := timezone
+		button := Button{Text: timezone, OnSelect: func(ctx context.Context, chatID int64) {
+			h.applySetting(ctx, chatID, h.profileService.SetTimezone(chatID, timezone))
+		}}
+		if i%2 == 0 {
+			rows = append(rows, []Button{button})
+		} else {
+			rows[len(rows)-1] = append(rows[len(rows)-1], button)
+		}
+	}
+
+	text := "🌍 <b>Alege fusul orar</b>\n\nPentru alt fus orar folosește <code>/setari fus Continent/Oraș</code>"
+	if err := h.SendMessageWithButtonRows(ctx, chatID, text, rows); err != nil {
+		log.Printf("Error sending timezone choices: %v", err)
+	}
+}
+
+// applySetting reports the outcome of a preference change and shows the updated settings
+func (h *botHandler) applySetting(ctx context.Context, chatID int64, err error) {
+	if err != nil {
+		log.Printf("Error updating settings for chat %d: %v", chatID, err)
+		h.SendMessage(ctx, chatID, "❌ <b>Eroare la salvarea setărilor</b>\n\nTe rugăm să încerci din nou mai târziu.")
+		return
+	}
+	h.sendSettings(ctx, chatID)
+}
+
+func formatSettings(profile *database.Profile) string {
+	notifications := "oprite"
+	if profile.NotificationsEnabled {
+		notifications = "pornite"
+	}
+	quietHours := "dezactivate"
+	if profile.QuietHoursEnabled {
+		quietHours = fmt.Sprintf("%02d:00–%02d:00", profile.QuietStart, profile.QuietEnd)
+	}
+	digest := "dezactivat"
+	if profile.DigestEnabled {
+		digest = fmt.Sprintf("zilnic la %02d:00", profile.DigestHour)
+	}
+	consent := "neacordat"
+	if profile.ConsentGiven && profile.ConsentAt != nil {
+		consent = fmt.Sprintf("acordat la %s", profile.ConsentAt.Format("02.01.2006"))
+	}
+
+	var response strings.Builder
+	response.WriteString("⚙️ <b>Setări</b>\n\n")
+	response.WriteString(fmt.Sprintf("🔔 Notificări: <b>%s</b>\n", notifications))
+	response.WriteString(fmt.Sprintf("🌍 Fus orar: <b>%s</b> (ora locală %s)\n",
+		html.EscapeString(profile.Location().String()), time.Now().In(profile.Location()).Format("15:04")))
+	response.WriteString(fmt.Sprintf("🌙 Ore de liniște: <b>%s</b>\n", quietHours))
+	response.WriteString(fmt.Sprintf("📬 Rezumat: <b>%s</b>\n", digest))
+	response.WriteString(fmt.Sprintf("🛡 Consimțământ prelucrare date: <b>%s</b>\n", consent))
+	response.WriteString("\n" + settingsUsage)
+	return response.String()
+}
+
+// setQuietHours applies "/setari liniste" arguments: an interval such as
+// 22-08, or pornit/oprit to toggle the current interval
+func (h *botHandler) setQuietHours(chatID int64, value string) error {
+	profile, err := h.profileService.GetProfile(chatID)
+	if err != nil {
+		return err
+	}
+
+	if enabled, ok := parseToggle(value); ok {
+		return h.profileService.SetQuietHours(chatID, enabled, profile.QuietStart, profile.QuietEnd)
+	}
+
+	from, to, found := strings.Cut(value, "-")
+	if !found {
+		return errInvalidSetting
+	}
+	start, startOk := parseHour(from)
+	end, endOk := parseHour(to)
+	if !startOk || !endOk || start == end {
+		return errInvalidSetting
+	}
+	return h.profileService.SetQuietHours(chatID, true, start, end)
+}
+
+// setDigest applies "/setari rezumat" arguments: the hour of the daily
+// digest, or pornit/oprit to toggle it
+func (h *botHandler) setDigest(chatID int64, value string) error {
+	profile, err := h.profileService.GetProfile(chatID)
+	if err != nil {
+		return err
+	}
+
+	if enabled, ok := parseToggle(value); ok {
+		return h.profileService.SetDigest(chatID, enabled, profile.DigestHour)
+	}
+
+	hour, ok := parseHour(value)
+	if !ok {
+		return errInvalidSetting
+	}
+	return h.profileService.SetDigest(chatID, true, hour)
+}
+
+// parseHour accepts an hour of the day written as 8, 08 or 08:00
+func parseHour(value string) (int, bool) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), ":00")
+	hour, err := strconv.Atoi(value)
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, false
+	}
+	return hour, true
+}
+
+// parseToggle accepts the Romanian (and English) words for on and off
+func parseToggle(value string) (bool, bool) {
+	switch strings.ToLower(value) {
+	case "pornit", "pornite", "da", "on":
+		return true, true
+	case "oprit", "oprite", "nu", "off":
+		return false, true
+	default:
+		return false, false
+	}
+}
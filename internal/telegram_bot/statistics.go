@@ -0,0 +1,134 @@
+package telegram_bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/analytics"
+	"github.com/andiq123/cetatenie-analyzer/internal/chart"
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/andiq123/cetatenie-analyzer/internal/dossier"
+	"github.com/go-telegram/bot/models"
+)
+
+const cmdStatistics = "statistici"
+
+// trendPeriod is how far back the revision compared against in the trend is
+const trendPeriod = 7 * 24 * time.Hour
+
+// statisticsCommand shows the aggregates of a year, or a line per year when
+// no year is given
+func (b *botService) statisticsCommand(ctx context.Context, update *models.Update) {
+	chatID := update.Message.Chat.ID
+
+	parts := strings.Fields(update.Message.Text)
+	if len(parts) < 2 {
+		b.sendYearsOverview(ctx, chatID)
+		return
+	}
+	year, err := strconv.Atoi(parts[1])
+	if err != nil {
+		b.bh.SendMessage(ctx, chatID, statisticsUsage)
+		return
+	}
+
+	summary, err := b.yearSummary(year)
+	if err != nil {
+		b.bh.SendMessage(ctx, chatID, fmt.Sprintf(errorMessage, html.EscapeString(err.Error())))
+		return
+	}
+
+	err = b.bh.SendMessageWithButtons(ctx, chatID, b.formatYearSummary(summary),
+		Button{Text: "📈 Grafic săptămânal", OnSelect: func(ctx context.Context, chatID int64) {
+			b.sendWeeklyChart(ctx, chatID, summary)
+		}})
+	if err != nil {
+		fmt.Printf("Error sending statistics: %v\n", err)
+	}
+}
+
+// yearSummary returns the aggregates of a year, cached per PDF revision
+func (b *botService) yearSummary(year int) (analytics.YearSummary, error) {
+	doc, err := b.processor.Document(year)
+	if err != nil {
+		return analytics.YearSummary{}, err
+	}
+	now := time.Now()
+	return b.statsCache.Get(doc, now, func() analytics.YearSummary {
+		return analytics.Summarize(doc, b.resolutionDates(year), now)
+	}), nil
+}
+
+func (b *botService) sendYearsOverview(ctx context.Context, chatID int64) {
+	var response strings.Builder
+	response.WriteString("📊 <b>Statistici pe ani</b>\n\n<pre>")
+	for _, source := range b.processor.Sources() {
+		summary, err := b.yearSummary(source.Year)
+		if err != nil {
+			response.WriteString(fmt.Sprintf("%d  ⚠️ indisponibil\n", source.Year))
+			continue
+		}
+		response.WriteString(fmt.Sprintf("%d  %6d/%-6d %5.1f%%\n", summary.Year, summary.Resolved, summary.Total, summary.PercentResolved()))
+	}
+	response.WriteString("</pre>\nrezolvate/listate. Folosește <code>/statistici AN</code> pentru detalii.")
+
+	b.bh.SendMessage(ctx, chatID, response.String())
+}
+
+func (b *botService) formatYearSummary(summary analytics.YearSummary) string {
+	var response strings.Builder
+	response.WriteString(fmt.Sprintf("📊 <b>Statistici %d</b>\n\n", summary.Year))
+	response.WriteString(fmt.Sprintf("📄 Dosare listate: <b>%d</b>\n", summary.Total))
+	response.WriteString(fmt.Sprintf("✅ Rezolvate: <b>%d</b> (%.1f%%)\n", summary.Resolved, summary.PercentResolved()))
+	response.WriteString(fmt.Sprintf("⏳ În procesare: <b>%d</b>\n", summary.Pending))
+	if summary.HighestResolved > 0 {
+		highest := dossier.Number{Value: summary.HighestResolved, Year: summary.Year}
+		response.WriteString(fmt.Sprintf("🔝 Cel mai mare număr rezolvat: <code>%s</code>\n", highest))
+	}
+	response.WriteString(fmt.Sprintf("⚡ Ritm: ~<b>%.0f</b> dosare/săptămână (mediana ultimelor %d săptămâni)\n", summary.WeeklyRate, decree.ThroughputWeeks))
+
+	if len(summary.LatestOrders) > 0 {
+		response.WriteString("\n🗓 <b>Ultimele ordine</b>\n")
+		for _, day := range summary.LatestOrders {
+			response.WriteString(fmt.Sprintf("• %s: %d dosare\n", day.Date.Format("02.01.2006"), day.Count))
+		}
+	}
+
+	response.WriteString("\n📈 <b>Tendință</b>: " + b.formatTrend(summary))
+	return response.String()
+}
+
+// formatTrend compares the summary with the revision recorded a week earlier
+func (b *botService) formatTrend(summary analytics.YearSummary) string {
+	previous, err := b.revisionService.GetRevisionBefore(summary.Year, time.Now().Add(-trendPeriod))
+	if err != nil {
+		fmt.Printf("Error getting previous revision: %v\n", err)
+	}
+	// Revisions recorded before resolved dossiers were counted cannot be compared
+	if previous == nil || (previous.Resolved == 0 && previous.HighestResolved > 0) {
+		return "<i>indisponibilă, nu există o revizie de acum o săptămână</i>"
+	}
+	return fmt.Sprintf("față de revizia din %s, <b>%+d</b> rezolvate și <b>%+d</b> dosare listate",
+		previous.CreatedAt.Format("02.01.2006"), summary.Resolved-previous.Resolved, summary.Total-previous.Entries)
+}
+
+func (b *botService) sendWeeklyChart(ctx context.Context, chatID int64, summary analytics.YearSummary) {
+	bars := make([]chart.Bar, len(summary.Weeks))
+	for i, week := range summary.Weeks {
+		bars[i] = chart.Bar{Label: week.Start.Format("02.01"), Value: week.Resolved}
+	}
+	image, err := chart.BarChart(bars)
+	if err != nil {
+		b.bh.SendMessage(ctx, chatID, fmt.Sprintf(errorMessage, html.EscapeString(err.Error())))
+		return
+	}
+
+	caption := fmt.Sprintf("📈 Dosare din %d rezolvate pe săptămână, ultimele %d săptămâni (data de început a fiecărei săptămâni)", summary.Year, decree.ThroughputWeeks)
+	if err := b.bh.SendPhoto(ctx, chatID, fmt.Sprintf("statistici_%d.png", summary.Year), image, caption); err != nil {
+		fmt.Printf("Error sending statistics chart: %v\n", err)
+	}
+}
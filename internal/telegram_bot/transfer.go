@@ -0,0 +1,194 @@
+package telegram_bot
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andiq123/cetatenie-analyzer/internal/bulk"
+	"github.com/andiq123/cetatenie-analyzer/internal/decree"
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	cmdExport = "export"
+
+	// maxImportSize is the largest document accepted for import
+	maxImportSize = 1 << 20
+	// maxReportedErrors limits the rejected rows listed in an import report
+	maxReportedErrors = 20
+	downloadTimeout   = 30 * time.Second
+)
+
+// isDocumentUpload matches messages carrying a document to import
+func isDocumentUpload(update *models.Update) bool {
+	return update.Message != nil && update.Message.Document != nil
+}
+
+// importDocumentHandler bulk-creates subscriptions from an uploaded CSV or
+// JSON document and replies with a per-row report
+func (h *botHandler) importDocumentHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	msg := update.Message
+	chatID := msg.Chat.ID
+	if !h.isAddressed(msg) {
+		return
+	}
+	if !h.canManageSubscriptions(ctx, msg) {
+		h.SendMessage(ctx, chatID, groupAdminOnly)
+		return
+	}
+
+	document := msg.Document
+	if document.FileSize > maxImportSize {
+		h.SendMessage(ctx, chatID, fmt.Sprintf(importFailed, fmt.Sprintf("fișierul depășește %d KB", maxImportSize/1024)))
+		return
+	}
+
+	data, err := h.downloadFile(ctx, document.FileID)
+	if err != nil {
+		log.Printf("Error downloading import document for chat %d: %v", chatID, err)
+		h.SendMessage(ctx, chatID, "❌ <b>Eroare la descărcarea fișierului</b>\n\nTe rugăm să încerci din nou mai târziu.")
+		return
+	}
+
+	rows, err := bulk.ParseDocument(document.FileName, data)
+	if err != nil {
+		h.SendMessage(ctx, chatID, fmt.Sprintf(importFailed, html.EscapeString(err.Error())))
+		return
+	}
+
+	h.SendMessage(ctx, chatID, h.importRows(chatID, rows))
+}
+
+// importRows subscribes the chat to every valid row and builds the report
+func (h *botHandler) importRows(chatID int64, rows []bulk.Row) string {
+	var added, relabeled, existing int
+	var rejected []bulk.Row
+	for _, row := range rows {
+		if row.Err == nil && utf8.RuneCountInString(row.Label) > maxLabelLength {
+			row.Err = fmt.Errorf("eticheta depășește %d caractere", maxLabelLength)
+		}
+		if row.Err != nil {
+			rejected = append(rejected, row)
+			continue
+		}
+
+		var err error
+		if row.Range != nil {
+			err = h.subscriptionService.CreateRangeSubscription(newRangeSubscription(chatID, *row.Range, row.Label))
+		} else {
+			err = h.subscriptionService.CreateSubscription(chatID, row.DecreeNumber, row.Label)
+		}
+		switch {
+		case err == nil:
+			added++
+		case strings.Contains(err.Error(), "subscription already exists") && row.Label != "":
+			if err := h.subscriptionService.SetLabel(chatID, row.DecreeNumber, row.Label); err != nil {
+				row.Err = fmt.Errorf("eroare la salvare")
+				rejected = append(rejected, row)
+				continue
+			}
+			relabeled++
+		case strings.Contains(err.Error(), "subscription already exists"):
+			existing++
+		default:
+			log.Printf("Error importing %s for chat %d: %v", row.DecreeNumber, chatID, err)
+			row.Err = fmt.Errorf("eroare la salvare")
+			rejected = append(rejected, row)
+		}
+	}
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf(importReport, len(rows), added, relabeled, existing, len(rejected)))
+	if len(rejected) > 0 {
+		report.WriteString("\n\n<b>Rânduri respinse:</b>\n")
+		for i, row := range rejected {
+			if i == maxReportedErrors {
+				report.WriteString(fmt.Sprintf("… și încă %d\n", len(rejected)-maxReportedErrors))
+				break
+			}
+			report.WriteString(fmt.Sprintf("• rândul %d: <code>%s</code> — %s\n", row.Line, html.EscapeString(row.Raw), html.EscapeString(row.Err.Error())))
+		}
+	}
+	return report.String()
+}
+
+func (h *botHandler) downloadFile(ctx context.Context, fileID string) ([]byte, error) {
+	file, err := h.instance.GetFile(ctx, &bot.GetFileParams{FileID: fileID})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, downloadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.instance.FileDownloadLink(file), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxImportSize))
+}
+
+// exportCommand sends the chat's subscriptions with their last known state as a CSV document
+func (h *botHandler) exportCommand(ctx context.Context, b *bot.Bot, update *models.Update) {
+	chatID := update.Message.Chat.ID
+	if !h.canManageSubscriptions(ctx, update.Message) {
+		h.SendMessage(ctx, chatID, groupAdminOnly)
+		return
+	}
+
+	subscriptions, err := h.subscriptionService.GetSubscriptions(chatID)
+	if err != nil {
+		h.SendMessage(ctx, chatID, "❌ <b>Eroare la obținerea abonamentelor</b>\n\nTe rugăm să încerci din nou mai târziu.")
+		return
+	}
+	if len(subscriptions) == 0 {
+		h.SendMessage(ctx, chatID, "📭 <b>Nu ai niciun abonament activ</b>\n\nFolosește comanda /adauga sau trimite un fișier CSV pentru a adăuga dosare.")
+		return
+	}
+
+	rows := make([]bulk.ExportRow, len(subscriptions))
+	for i, subscription := range subscriptions {
+		state := "necunoscută"
+		if subscription.IsRange() {
+			state = "interval urmărit"
+		} else if subscription.LastState != nil {
+			state = stateName(decree.FindState(*subscription.LastState))
+		}
+		rows[i] = bulk.ExportRow{
+			DecreeNumber: subscription.DecreeNumber,
+			Label:        subscription.Label,
+			Note:         subscription.Note,
+			State:        state,
+			CheckedAt:    subscription.LastCheckedAt,
+		}
+	}
+
+	document, err := bulk.WriteCSV(rows)
+	if err != nil {
+		log.Printf("Error writing export for chat %d: %v", chatID, err)
+		h.SendMessage(ctx, chatID, "❌ <b>Eroare la exportul abonamentelor</b>\n\nTe rugăm să încerci din nou mai târziu.")
+		return
+	}
+
+	filename := fmt.Sprintf("abonamente_%s.csv", time.Now().Format("2006-01-02"))
+	if err := h.SendDocument(ctx, chatID, filename, document, fmt.Sprintf(exportCaption, len(rows))); err != nil {
+		log.Printf("Error sending export to chat %d: %v", chatID, err)
+	}
+}
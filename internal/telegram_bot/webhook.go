@@ -0,0 +1,160 @@
+package telegram_bot
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+)
+
+const (
+	// secretTokenHeader is the header Telegram uses to send the webhook secret token
+	secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+	defaultWebhookListen = ":8080"
+	defaultWebhookPath   = "/telegram/webhook"
+	// webhookSecretLength is the length of the secret generated when none is configured
+	webhookSecretLength  = 32
+	webhookShutdownGrace = 5 * time.Second
+)
+
+// webhookConfig holds the optional webhook settings. Webhook mode is enabled
+// by setting TELEGRAM_WEBHOOK_URL to the public base URL of the bot (e.g. the
+// reverse proxy address); otherwise the bot falls back to long polling.
+type webhookConfig struct {
+	publicURL   string
+	listenAddr  string
+	path        string
+	secretToken string
+}
+
+// loadWebhookConfig reads the webhook settings from the environment:
+// TELEGRAM_WEBHOOK_URL, TELEGRAM_WEBHOOK_LISTEN, TELEGRAM_WEBHOOK_PATH and
+// TELEGRAM_WEBHOOK_SECRET. Updates are trusted as they are, admin commands
+// included, so only Telegram may deliver them: without a configured secret a
+// random one is generated and registered with Telegram for this run.
+func loadWebhookConfig() webhookConfig {
+	cfg := webhookConfig{
+		publicURL:   strings.TrimRight(os.Getenv("TELEGRAM_WEBHOOK_URL"), "/"),
+		listenAddr:  os.Getenv("TELEGRAM_WEBHOOK_LISTEN"),
+		path:        os.Getenv("TELEGRAM_WEBHOOK_PATH"),
+		secretToken: os.Getenv("TELEGRAM_WEBHOOK_SECRET"),
+	}
+
+	if cfg.listenAddr == "" {
+		cfg.listenAddr = defaultWebhookListen
+	}
+	if cfg.path == "" {
+		cfg.path = defaultWebhookPath
+	}
+	if !strings.HasPrefix(cfg.path, "/") {
+		cfg.path = "/" + cfg.path
+	}
+	if cfg.enabled() && cfg.secretToken == "" {
+		cfg.secretToken = bot.RandomString(webhookSecretLength)
+		log.Printf("TELEGRAM_WEBHOOK_SECRET is not set, using a secret generated for this run")
+	}
+	return cfg
+}
+
+// enabled reports whether the bot should receive updates through a webhook
+func (c webhookConfig) enabled() bool {
+	return c.publicURL != ""
+}
+
+// botOptions configures the bot to check the secret token of the updates it receives
+func (c webhookConfig) botOptions() []bot.Option {
+	if c.secretToken == "" {
+		return nil
+	}
+	return []bot.Option{bot.WithWebhookSecretToken(c.secretToken)}
+}
+
+// url returns the address registered with Telegram
+func (c webhookConfig) url() string {
+	return c.publicURL + c.path
+}
+
+// startWebhook registers the webhook with Telegram, serves updates on the
+// configured listener until ctx is cancelled and deregisters it on shutdown
+func (h *botHandler) startWebhook(ctx context.Context, cfg webhookConfig) error {
+	if cfg.secretToken == "" {
+		return fmt.Errorf("webhook-ul nu poate porni fără un secret")
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(cfg.path, h.webhookHandler(cfg))
+
+	server := &http.Server{
+		Addr:              cfg.listenAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	_, err := h.instance.SetWebhook(ctx, &bot.SetWebhookParams{
+		URL:         cfg.url(),
+		SecretToken: cfg.secretToken,
+	})
+	if err != nil {
+		return fmt.Errorf("eroare la setarea webhook-ului: %w", err)
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	go h.instance.StartWebhook(ctx)
+
+	log.Printf("🤖 Pornire bot Telegram în modul webhook pe %s%s...", cfg.listenAddr, cfg.path)
+
+	select {
+	case <-ctx.Done():
+	case err = <-serverErr:
+		err = fmt.Errorf("eroare la serverul webhook: %w", err)
+	}
+
+	// The parent context is done at this point, use a fresh one for cleanup
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), webhookShutdownGrace)
+	defer cancel()
+
+	if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil {
+		log.Printf("Error shutting down webhook server: %v", shutdownErr)
+	}
+	if _, deleteErr := h.instance.DeleteWebhook(shutdownCtx, &bot.DeleteWebhookParams{}); deleteErr != nil {
+		log.Printf("Error deleting webhook: %v", deleteErr)
+	}
+
+	return err
+}
+
+// webhookHandler only accepts POST requests carrying the configured secret
+// token and forwards them to the bot, which dispatches them to the same
+// handlers used in polling mode. Without a secret every request is refused.
+func (h *botHandler) webhookHandler(cfg webhookConfig) http.Handler {
+	forward := h.instance.WebhookHandler()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		token := r.Header.Get(secretTokenHeader)
+		if cfg.secretToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(cfg.secretToken)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		forward(w, r)
+	})
+}
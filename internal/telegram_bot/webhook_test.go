@@ -0,0 +1,123 @@
+package telegram_bot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// recordedUpdate is an update as Telegram posts it to the webhook
+const recordedUpdate = `{"update_id":1001,"message":{"message_id":7,"date":1760000000,` +
+	`"chat":{"id":42,"type":"private"},"from":{"id":42,"is_bot":false,"first_name":"Ana"},"text":"123/RD/2023"}}`
+
+// newWebhookServer serves the webhook of a bot whose updates are sent to the returned channel
+func newWebhookServer(t *testing.T, cfg webhookConfig) (*httptest.Server, <-chan *models.Update) {
+	t.Helper()
+	received := make(chan *models.Update, 1)
+
+	opts := append([]bot.Option{
+		bot.WithSkipGetMe(),
+		bot.WithDefaultHandler(func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			received <- update
+		}),
+	}, cfg.botOptions()...)
+	instance, err := bot.New("123:test", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go instance.StartWebhook(ctx)
+
+	h := &botHandler{instance: instance}
+	server := httptest.NewServer(h.webhookHandler(cfg))
+	t.Cleanup(server.Close)
+	return server, received
+}
+
+func TestWebhookHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		secret     string
+		method     string
+		header     string
+		wantStatus int
+		wantUpdate bool
+	}{
+		{name: "correct secret", secret: "s3cret", method: http.MethodPost, header: "s3cret", wantStatus: http.StatusOK, wantUpdate: true},
+		{name: "wrong secret", secret: "s3cret", method: http.MethodPost, header: "guess", wantStatus: http.StatusUnauthorized},
+		{name: "missing secret", secret: "s3cret", method: http.MethodPost, wantStatus: http.StatusUnauthorized},
+		{name: "no secret configured", method: http.MethodPost, wantStatus: http.StatusUnauthorized},
+		{name: "no secret configured, any header", method: http.MethodPost, header: "anything", wantStatus: http.StatusUnauthorized},
+		{name: "not a POST", secret: "s3cret", method: http.MethodGet, header: "s3cret", wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := webhookConfig{publicURL: "https://bot.example.org", path: defaultWebhookPath, secretToken: tt.secret}
+			server, received := newWebhookServer(t, cfg)
+
+			req, err := http.NewRequest(tt.method, server.URL+cfg.path, strings.NewReader(recordedUpdate))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.header != "" {
+				req.Header.Set(secretTokenHeader, tt.header)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			select {
+			case update := <-received:
+				if !tt.wantUpdate {
+					t.Fatalf("update %d was dispatched, want it rejected", update.ID)
+				}
+				if update.ID != 1001 || update.Message == nil || update.Message.Text != "123/RD/2023" || update.Message.Chat.ID != 42 {
+					t.Errorf("dispatched %+v, want the recorded update", update)
+				}
+			case <-time.After(200 * time.Millisecond):
+				if tt.wantUpdate {
+					t.Error("the update was not dispatched")
+				}
+			}
+		})
+	}
+}
+
+func TestLoadWebhookConfigGeneratesSecret(t *testing.T) {
+	t.Setenv("TELEGRAM_WEBHOOK_URL", "https://bot.example.org/")
+	t.Setenv("TELEGRAM_WEBHOOK_SECRET", "")
+
+	cfg := loadWebhookConfig()
+	if len(cfg.secretToken) != webhookSecretLength {
+		t.Fatalf("secret %q, want a generated secret of %d characters", cfg.secretToken, webhookSecretLength)
+	}
+	if other := loadWebhookConfig(); other.secretToken == cfg.secretToken {
+		t.Error("two runs generated the same secret")
+	}
+
+	t.Setenv("TELEGRAM_WEBHOOK_SECRET", "configured")
+	if cfg := loadWebhookConfig(); cfg.secretToken != "configured" {
+		t.Errorf("secret %q, want the configured one", cfg.secretToken)
+	}
+
+	// Polling mode needs no secret
+	t.Setenv("TELEGRAM_WEBHOOK_URL", "")
+	t.Setenv("TELEGRAM_WEBHOOK_SECRET", "")
+	if cfg := loadWebhookConfig(); cfg.enabled() || cfg.secretToken != "" {
+		t.Errorf("polling config %+v", cfg)
+	}
+}